@@ -0,0 +1,332 @@
+// Package envtag defines an Analyzer that reports env struct tag mistakes which
+// config.Load would otherwise only report when it runs.
+package envtag
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/stuft2/envchain/config/envchainrt"
+	"github.com/stuft2/envchain/internal/envtypes"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const Doc = `check env struct tags used by config.Load
+
+The envtag analyzer interprets env and envPrefix tags with the same parser as
+config.Load and reports, at the offending tag:
+
+- invalid tags, such as unsupported options,
+- field types Load cannot decode, such as time.Time without layout=,
+- a default that is not one of the oneof values,
+- keys read by more than one field of the same struct, including fields of
+  nested and embedded structs and the KEY_FILE companion of a field, such as
+  LOG_FILE beside LOG.
+
+Only packages that import github.com/stuft2/envchain/config are checked, since
+other libraries read env tags with rules of their own.`
+
+const configPath = "github.com/stuft2/envchain/config"
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "envtag",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var (
+	autoKeys bool
+	parsers  string
+)
+
+func init() {
+	Analyzer.Flags.BoolVar(&autoKeys, "auto", false, "derive missing keys from field names, as config.WithAutoKeys does")
+	Analyzer.Flags.StringVar(&parsers, "parsers", "", "comma-separated list of types with parsers registered at run time, such as *regexp.Regexp")
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	if !importsConfig(pass.Pkg) {
+		return nil, nil
+	}
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	c := &checker{
+		pass:     pass,
+		tags:     map[token.Pos]*ast.BasicLit{},
+		auto:     map[*types.Struct]bool{},
+		parsers:  map[string]bool{},
+		reported: map[string]bool{},
+	}
+	for _, name := range strings.Split(parsers, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			c.parsers[name] = true
+		}
+	}
+
+	var structs []*types.Struct
+	inspect.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		node := n.(*ast.StructType)
+		st, ok := pass.TypesInfo.Types[node].Type.(*types.Struct)
+		if !ok {
+			return
+		}
+		for _, field := range node.Fields.List {
+			for _, name := range field.Names {
+				c.tags[name.Pos()] = field.Tag
+			}
+			if len(field.Names) == 0 {
+				c.tags[embeddedName(field.Type).Pos()] = field.Tag
+			}
+		}
+		structs = append(structs, st)
+	})
+
+	// Structs nested beneath an auto struct derive their keys too, so they are
+	// checked with auto set wherever they are declared.
+	for _, st := range structs {
+		c.markAuto(st, autoKeys, nil)
+	}
+	for _, st := range structs {
+		c.checkStruct(st)
+	}
+	return nil, nil
+}
+
+// importsConfig reports whether pkg imports the config package directly.
+func importsConfig(pkg *types.Package) bool {
+	return slices.ContainsFunc(pkg.Imports(), func(imported *types.Package) bool {
+		return imported.Path() == configPath
+	})
+}
+
+type checker struct {
+	pass     *analysis.Pass
+	tags     map[token.Pos]*ast.BasicLit
+	auto     map[*types.Struct]bool
+	parsers  map[string]bool
+	reported map[string]bool
+}
+
+// entry is a key read by a field beneath the struct being checked.
+type entry struct {
+	key  string
+	path string
+	// top is the index of the struct's own field the key is read through.
+	top int
+}
+
+// checkStruct reports the tag mistakes in the fields of st, and keys read by
+// more than one of them.
+func (c *checker) checkStruct(st *types.Struct) {
+	auto := autoKeys || c.auto[st]
+
+	var entries []entry
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+
+		shape := envtypes.Shape(field.Type())
+		compiled := envchainrt.CompileField(field.Name(), reflect.StructTag(st.Tag(i)), field.Embedded(), shape, auto)
+		if err := compiled.Err(); err != nil {
+			c.report(field, "field %s: %v", field.Name(), err)
+			continue
+		}
+
+		info := compiled.Info()
+		switch info.Kind {
+		case envchainrt.FieldValue:
+			if err := envtypes.CheckValue(field.Type(), info.Tag.Format, info.Tag.Layout, c.parsers); err != nil {
+				c.report(field, "field %s: %v", field.Name(), err)
+				continue
+			}
+			c.checkDefault(field, info.Tag)
+		}
+
+		for _, e := range c.keys(field, info, "", "", []types.Type{st}) {
+			e.top = i
+			entries = append(entries, e)
+		}
+	}
+
+	seen := map[string]entry{}
+	duplicated := map[int]bool{}
+	for _, e := range entries {
+		first, ok := seen[e.key]
+		if !ok {
+			seen[e.key] = e
+			continue
+		}
+		// Duplicates within one nested struct are reported where it is declared,
+		// and each field is reported once.
+		if first.top == e.top || duplicated[e.top] {
+			continue
+		}
+		duplicated[e.top] = true
+		c.report(st.Field(e.top), "env key %q of %s is also read by %s", e.key, e.path, first.path)
+	}
+}
+
+// checkDefault reports a default that oneof would reject. Only string fields are
+// checked, since other types compare parsed values.
+func (c *checker) checkDefault(field *types.Var, tag envchainrt.FieldTag) {
+	if !tag.HasDefault || len(tag.OneOf) == 0 || !envtypes.IsString(underlyingValue(field.Type())) {
+		return
+	}
+	if !slices.Contains(tag.OneOf, tag.Default) {
+		c.report(field, "field %s: default %q is not one of %s", field.Name(), tag.Default, strings.Join(tag.OneOf, "|"))
+	}
+}
+
+// underlyingValue unwraps pointers and config.Optional to the decoded type.
+func underlyingValue(t types.Type) types.Type {
+	for {
+		if pointer, ok := t.Underlying().(*types.Pointer); ok {
+			t = pointer.Elem()
+			continue
+		}
+		if named, ok := types.Unalias(t).(*types.Named); ok && envtypes.IsOptional(t) {
+			t = named.TypeArgs().At(0)
+			continue
+		}
+		return t
+	}
+}
+
+// keys lists the keys read by field, whose compiled tags are info, beneath the
+// given key prefix and field path. visiting guards against recursive types.
+func (c *checker) keys(field *types.Var, info envchainrt.FieldInfo, prefix, path string, visiting []types.Type) []entry {
+	path += field.Name()
+
+	switch info.Kind {
+	case envchainrt.FieldValue:
+		entries := []entry{{key: prefix + info.Tag.Key, path: path}}
+		// Fields that do not read their value from a file take a path from the
+		// KEY_FILE companion, so a field declaring that key collides with it.
+		if !info.Tag.File {
+			entries = append(entries, entry{key: prefix + info.Tag.Key + "_FILE", path: path})
+		}
+		for _, alias := range info.Tag.Aliases {
+			entries = append(entries, entry{key: prefix + alias, path: path})
+		}
+		return entries
+	case envchainrt.FieldNested, envchainrt.FieldCollection:
+		st, elem := nestedStruct(field.Type())
+		if st == nil || slices.ContainsFunc(visiting, func(t types.Type) bool { return types.Identical(t, elem) }) {
+			return nil
+		}
+		prefix += info.Prefix
+		if info.Kind == envchainrt.FieldCollection {
+			placeholder := "<KEY>"
+			if _, ok := field.Type().Underlying().(*types.Slice); ok {
+				placeholder = "<N>"
+			}
+			prefix += placeholder + "_"
+			path += "[]"
+		}
+
+		var entries []entry
+		for i := 0; i < st.NumFields(); i++ {
+			child := st.Field(i)
+			if !child.Exported() {
+				continue
+			}
+			compiled := envchainrt.CompileField(child.Name(), reflect.StructTag(st.Tag(i)), child.Embedded(), envtypes.Shape(child.Type()), info.Auto)
+			if compiled.Err() != nil {
+				continue
+			}
+			entries = append(entries, c.keys(child, compiled.Info(), prefix, path+".", append(visiting, elem))...)
+		}
+		return entries
+	default:
+		return nil
+	}
+}
+
+// markAuto records the structs nested beneath st, directly or in collections,
+// that derive their keys from field names.
+func (c *checker) markAuto(st *types.Struct, auto bool, visiting []*types.Struct) {
+	if slices.Contains(visiting, st) {
+		return
+	}
+	if auto {
+		c.auto[st] = true
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+		compiled := envchainrt.CompileField(field.Name(), reflect.StructTag(st.Tag(i)), field.Embedded(), envtypes.Shape(field.Type()), auto)
+		info := compiled.Info()
+		if compiled.Err() != nil || (info.Kind != envchainrt.FieldNested && info.Kind != envchainrt.FieldCollection) {
+			continue
+		}
+		if child, _ := nestedStruct(field.Type()); child != nil {
+			c.markAuto(child, info.Auto, append(visiting, st))
+		}
+	}
+}
+
+// nestedStruct returns the struct loaded by a nested or collection field of type
+// t, along with the type that declares it.
+func nestedStruct(t types.Type) (*types.Struct, types.Type) {
+	switch u := t.Underlying().(type) {
+	case *types.Slice:
+		t = u.Elem()
+	case *types.Map:
+		t = u.Elem()
+	}
+	if pointer, ok := t.Underlying().(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+	st, _ := t.Underlying().(*types.Struct)
+	return st, t
+}
+
+// report reports a problem at the tag of field, or at the field when it has no
+// tag, once per position and message.
+func (c *checker) report(field *types.Var, format string, args ...any) {
+	var node analysis.Range = posRange(field.Pos())
+	if tag := c.tags[field.Pos()]; tag != nil {
+		node = tag
+	}
+	msg := fmt.Sprintf(format, args...)
+	if id := fmt.Sprint(node.Pos(), msg); !c.reported[id] {
+		c.reported[id] = true
+		c.pass.ReportRangef(node, "%s", msg)
+	}
+}
+
+// embeddedName returns the identifier go/types uses as the position of an
+// embedded field of type expr.
+func embeddedName(expr ast.Expr) ast.Expr {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.SelectorExpr:
+			return e.Sel
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		default:
+			return expr
+		}
+	}
+}
+
+type posRange token.Pos
+
+func (p posRange) Pos() token.Pos { return token.Pos(p) }
+func (p posRange) End() token.Pos { return token.Pos(p) }
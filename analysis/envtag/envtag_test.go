@@ -0,0 +1,12 @@
+package envtag_test
+
+import (
+	"testing"
+
+	"github.com/stuft2/envchain/analysis/envtag"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), envtag.Analyzer, "a", "b")
+}
@@ -0,0 +1,64 @@
+package a
+
+import (
+	"time"
+
+	"github.com/stuft2/envchain/config"
+)
+
+func load() (Config, error) {
+	var cfg Config
+	err := config.Load(&cfg)
+	return cfg, err
+}
+
+type Config struct {
+	Port    int           `env:"PORT,default"` // want `field Port: unsupported env option "default"`
+	Started time.Time     `env:"STARTED"`      // want `field Started: time.Time fields require layout`
+	Stopped time.Time     `env:"STOPPED,layout=2006-01-02"`
+	Channel chan int      `env:"CHANNEL"`                           // want `field Channel: unsupported field type chan int`
+	Mode    string        `env:"MODE,default=debug,oneof=dev|prod"` // want `field Mode: default "debug" is not one of dev\|prod`
+	Level   *string       `env:"LEVEL,default=info,oneof=info|warn"`
+	Timeout time.Duration `env:"TIMEOUT,default=5s"`
+	Host    string        `env:"TIMEOUT"` // want `env key "TIMEOUT" of Host is also read by Timeout`
+	Addr    string        `env:"ADDR,alias=HOST_ADDR"`
+	Legacy  string        `env:"HOST_ADDR"` // want `env key "HOST_ADDR" of Legacy is also read by Addr`
+	Log     string        `env:"LOG"`
+	LogFile string        `env:"LOG_FILE"` // want `env key "LOG_FILE" of LogFile is also read by Log`
+	Cert    string        `env:"CERT,file"`
+	CertRaw string        `env:"CERT_FILE"`
+
+	DB      Database   `envPrefix:"DB_"`
+	Primary Database   `envPrefix:"DB_"` // want `env key "DB_HOST" of Primary.Host is also read by DB.Host`
+	Nodes   []Database `envPrefix:"NODE_"`
+
+	Hostname string `env:"HOST"`
+	Database        // want `env key "HOST" of Database.Host is also read by Hostname`
+
+	Untagged chan int
+	internal chan int `env:"INTERNAL"`
+}
+
+type Database struct {
+	Host   string `env:"HOST"`
+	Server string `env:"HOST"` // want `env key "HOST" of Server is also read by Host`
+}
+
+type Root struct {
+	Auto Derived `env:",auto"`
+	Embedded
+}
+
+type Embedded struct {
+	Host string `env:"HOST"`
+}
+
+// Derived is only loaded with auto keys, so its untagged and keyless fields are valid.
+type Derived struct {
+	MaxConns int
+	Name     string `env:",required"`
+}
+
+type Keyless struct {
+	Name string `env:",required"` // want `field Name: env tag must start with a key`
+}
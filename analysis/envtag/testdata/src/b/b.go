@@ -0,0 +1,7 @@
+// Package b reads env tags with another library, so its tags are not checked.
+package b
+
+type Config struct {
+	Port int    `env:"PORT,notEmpty"`
+	Host string `env:"PORT"`
+}
@@ -0,0 +1,7 @@
+// Package config stands in for the real config package, which the analyzer
+// only needs to see imported.
+package config
+
+func Load(target any) error {
+	return nil
+}
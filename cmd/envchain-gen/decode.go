@@ -0,0 +1,317 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"github.com/stuft2/envchain/config/envchainrt"
+	"github.com/stuft2/envchain/internal/envtypes"
+)
+
+// value emits the statements that load the value field f, addressed by access.
+// Fields of built-in types are decoded by generated functions; the rest, such as
+// types that decode themselves from text and types with parsers registered at run
+// time, are loaded by envchainrt.LoadValue.
+func (g *generator) value(depth int, stVar, access, ref string, field *types.Var, info envchainrt.FieldInfo) {
+	t := field.Type()
+	if !g.direct(t, info.Tag.Format) {
+		g.line(depth, "envchainrt.LoadValue(%s, %s, &%s)", stVar, ref, access)
+		return
+	}
+
+	decode := g.decoder(field, t, info.Tag.Format, info.Tag.Layout)
+	g.line(depth, "switch raw, action := %s.Lookup(%s, %s); action {", stVar, ref, g.zeroExpr(field, t, access))
+	g.line(depth, "case envchainrt.Decode:")
+	g.line(depth+1, "if v, err := %s(%s, raw); %s.Decoded(%q, err) {", decode, ref, stVar, reflectName(t))
+	g.line(depth+2, "%s = v", access)
+	g.line(depth+1, "}")
+	g.line(depth, "case envchainrt.Keep:")
+	g.line(depth+1, "%s.Keep(%s)", stVar, g.existingExpr(t, access))
+	g.line(depth, "}")
+}
+
+// direct reports whether values of type t with the given format are decoded by
+// generated code, following the order in which config.Load picks a decoder.
+func (g *generator) direct(t types.Type, format string) bool {
+	t = types.Unalias(t)
+	if g.opts.parsers[envtypes.TypeName(t)] || unexportedForeign(t, g.pkg.Types) != nil {
+		return false
+	}
+
+	switch format {
+	case "json":
+		if !envtypes.IsOptional(t) {
+			return false
+		}
+	case "base64", "hex":
+		if envtypes.IsString(t) || isBytes(t) {
+			return true
+		}
+	}
+
+	switch {
+	case envtypes.IsNamed(t, "time", "Duration"), envtypes.IsNamed(t, "time", "Time"), envtypes.IsNamed(t, "net/url", "URL"):
+		return true
+	case envtypes.IsOptional(t):
+		return g.direct(t.(*types.Named).TypeArgs().At(0), format)
+	case envtypes.IsUnmarshaler(t):
+		return false
+	}
+
+	switch u := t.(type) {
+	case *types.Pointer:
+		// Pointers to pointers are left to the runtime, which handles any depth.
+		_, nested := u.Elem().Underlying().(*types.Pointer)
+		return !nested && g.direct(u.Elem(), format)
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Info()&(types.IsString|types.IsBoolean|types.IsInteger|types.IsFloat) != 0 && u.Kind() != types.Uintptr
+	case *types.Slice:
+		return g.direct(u.Elem(), format)
+	case *types.Map:
+		keyFormat := format
+		if keyFormat == "json" || keyFormat == "base64" || keyFormat == "hex" {
+			keyFormat = ""
+		}
+		return g.direct(u.Key(), keyFormat) && g.direct(u.Elem(), format)
+	}
+	return false
+}
+
+// isBytes reports whether t is a slice of uint8 that a []byte converts to.
+func isBytes(t types.Type) bool {
+	slice, ok := t.Underlying().(*types.Slice)
+	return ok && types.Identical(slice.Elem(), types.Typ[types.Uint8])
+}
+
+// decoder returns the name of the function that decodes and checks a value of
+// type t, as the setter config.Load compiles for it does, emitting the function
+// on first use. t must be one direct accepts.
+func (g *generator) decoder(field *types.Var, t types.Type, format, layout string) string {
+	t = types.Unalias(t)
+	id := types.TypeString(t, nil) + "," + format + "," + layout
+	if name, ok := g.decoders[id]; ok {
+		return name
+	}
+
+	typeName, _ := g.typeString(field, t)
+	var body strings.Builder
+	line := func(depth int, format string, args ...any) {
+		body.WriteString(strings.Repeat("\t", depth))
+		fmt.Fprintf(&body, format, args...)
+		body.WriteByte('\n')
+	}
+	fail := func(depth int, err string) {
+		line(depth, "if err != nil {")
+		line(depth+1, "return v, %s", err)
+		line(depth, "}")
+	}
+
+	basic, _ := t.Underlying().(*types.Basic)
+	switch {
+	case (format == "base64" || format == "hex") && envtypes.IsString(t):
+		line(1, "b, err := f.DecodeBinary(raw)")
+		fail(1, "err")
+		line(1, "return %s(b), f.CheckString(string(b))", typeName)
+	case (format == "base64" || format == "hex") && isBytes(t):
+		line(1, "b, err := f.DecodeBinary(raw)")
+		fail(1, "err")
+		line(1, "return %s, f.CheckCollection(len(b), false, %q)", convert(typeName, "[]byte", "b"), reflectName(t))
+	case envtypes.IsNamed(t, "time", "Duration"):
+		line(1, "v, err = %s.ParseDuration(raw)", g.use("time"))
+		fail(1, "err")
+		line(1, "return v, f.CheckDuration(v, %q)", reflectName(t))
+	case envtypes.IsNamed(t, "time", "Time"):
+		line(1, "v, err = %s.Parse(%q, raw)", g.use("time"), layout)
+		fail(1, "err")
+		line(1, "return v, f.CheckOther(%q)", reflectName(t))
+	case envtypes.IsNamed(t, "net/url", "URL"):
+		line(1, "u, err := envchainrt.ParseURL(raw)")
+		fail(1, "err")
+		line(1, "return *u, f.CheckOther(%q)", reflectName(t))
+	case envtypes.IsOptional(t):
+		elem := g.decoder(field, t.(*types.Named).TypeArgs().At(0), format, layout)
+		line(1, "e, err := %s(f, raw)", elem)
+		fail(1, "err")
+		line(1, "return %s.Some(e), nil", g.use(configPath))
+	default:
+		switch u := t.Underlying().(type) {
+		case *types.Pointer:
+			elem := g.decoder(field, u.Elem(), format, layout)
+			line(1, "e, err := %s(f, raw)", elem)
+			fail(1, "err")
+			line(1, "return &e, nil")
+		case *types.Slice:
+			elem := g.decoder(field, u.Elem(), format, layout)
+			line(1, "parts, err := f.Split(raw)")
+			fail(1, "err")
+			line(1, "elem := f.Element()")
+			line(1, "v = make(%s, len(parts))", typeName)
+			line(1, "for i, part := range parts {")
+			line(2, "if v[i], err = %s(elem, part); err != nil {", elem)
+			line(3, "return v, envchainrt.ElementError(err, \"element %%d\", i)")
+			line(2, "}")
+			line(1, "}")
+			line(1, "return v, f.CheckCollection(len(v), %t, %q)", envtypes.IsString(u.Elem()), reflectName(t))
+		case *types.Map:
+			keyFormat := format
+			if keyFormat == "json" || keyFormat == "base64" || keyFormat == "hex" {
+				keyFormat = ""
+			}
+			key := g.decoder(field, u.Key(), keyFormat, layout)
+			elem := g.decoder(field, u.Elem(), format, layout)
+			line(1, "entries, err := f.SplitMap(raw)")
+			fail(1, "err")
+			line(1, "keyField, elemField := f.MapKey(), f.Element()")
+			line(1, "v = make(%s, len(entries))", typeName)
+			line(1, "for _, entry := range %s.Sorted(%s.Keys(entries)) {", g.use("slices"), g.use("maps"))
+			line(2, "k, err := %s(keyField, entry)", key)
+			fail(2, "envchainrt.ElementError(err, \"key %q\", entry)")
+			line(2, "e, err := %s(elemField, entries[entry])", elem)
+			fail(2, "envchainrt.ElementError(err, \"key %q\", entry)")
+			line(2, "v[k] = e")
+			line(1, "}")
+			line(1, "return v, f.CheckCollection(len(v), %t, %q)", envtypes.IsString(u.Elem()), reflectName(t))
+		default:
+			g.decodeBasic(line, fail, basic, typeName, reflectName(t), format)
+		}
+	}
+
+	name := fmt.Sprintf("envchain%sDecode%d", upperFirst(g.typeName), len(g.decoders))
+	g.decoders[id] = name
+	fmt.Fprintf(&g.funcs, "\nfunc %s(f *envchainrt.Field, raw string) (v %s, err error) {\n", name, typeName)
+	g.funcs.WriteString(body.String())
+	g.funcs.WriteString("}\n")
+	return name
+}
+
+// decodeBasic emits the body of a decoder for a string, boolean or numeric type.
+func (g *generator) decodeBasic(line func(int, string, ...any), fail func(int, string), basic *types.Basic, typeName, name, format string) {
+	strconvName := g.use("strconv")
+	bits := func() string {
+		switch basic.Kind() {
+		case types.Int, types.Uint:
+			return strconvName + ".IntSize"
+		case types.Int8, types.Uint8:
+			return "8"
+		case types.Int16, types.Uint16:
+			return "16"
+		case types.Int32, types.Uint32, types.Float32:
+			return "32"
+		default:
+			return "64"
+		}
+	}
+
+	switch info := basic.Info(); {
+	case info&types.IsString != 0:
+		line(1, "return %s, f.CheckString(raw)", convert(typeName, "string", "raw"))
+	case info&types.IsBoolean != 0:
+		line(1, "b, err := %s.ParseBool(raw)", strconvName)
+		fail(1, "err")
+		line(1, "return %s, f.CheckOther(%q)", convert(typeName, "bool", "b"), name)
+	case info&types.IsUnsigned != 0:
+		line(1, "n, err := %s.ParseUint(raw, 10, %s)", strconvName, bits())
+		fail(1, "err")
+		line(1, "return %s, f.CheckUint(n, %q)", convert(typeName, "uint64", "n"), name)
+	case info&types.IsInteger != 0:
+		if format == "bytes" {
+			line(1, "n, err := f.ParseInt(raw, %s)", bits())
+		} else {
+			line(1, "n, err := %s.ParseInt(raw, 10, %s)", strconvName, bits())
+		}
+		fail(1, "err")
+		line(1, "return %s, f.CheckInt(n, %q)", convert(typeName, "int64", "n"), name)
+	default:
+		line(1, "n, err := %s.ParseFloat(raw, %s)", strconvName, bits())
+		fail(1, "err")
+		line(1, "return %s, f.CheckFloat(n, %q)", convert(typeName, "float64", "n"), name)
+	}
+}
+
+// convert returns expr, of type from, converted to the type spelled typeName.
+func convert(typeName, from, expr string) string {
+	if typeName == from {
+		return expr
+	}
+	return typeName + "(" + expr + ")"
+}
+
+// zeroExpr returns an expression reporting whether access, of type t, holds the
+// zero value of its type.
+func (g *generator) zeroExpr(field *types.Var, t types.Type, access string) string {
+	t = types.Unalias(t)
+	switch {
+	case envtypes.IsOptional(t):
+		return "!" + access + ".IsSet()"
+	case envtypes.IsNamed(t, "time", "Time"), envtypes.IsNamed(t, "net/url", "URL"):
+		typeName, _ := g.typeString(field, t)
+		return access + " == (" + typeName + "{})"
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map:
+		return access + " == nil"
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsString != 0:
+			return access + ` == ""`
+		case u.Info()&types.IsBoolean != 0:
+			return "!" + access
+		}
+	}
+	return access + " == 0"
+}
+
+// existingExpr formats access, of type t, as config.Load describes a value a
+// field kept: pointers are dereferenced and strings are used as they are.
+func (g *generator) existingExpr(t types.Type, access string) string {
+	if pointer, ok := types.Unalias(t).(*types.Pointer); ok {
+		t, access = pointer.Elem(), "*"+access
+	}
+	if envtypes.IsString(t) {
+		if basic, ok := types.Unalias(t).(*types.Basic); ok && basic.Kind() == types.String {
+			return access
+		}
+		return "string(" + access + ")"
+	}
+	return g.use("fmt") + ".Sprint(" + access + ")"
+}
+
+// use returns the name under which the generated file imports the package at path.
+func (g *generator) use(path string) string {
+	return g.qualifier(types.NewPackage(path, lastElement(path)))
+}
+
+// reflectName spells t as reflect.Type.String does, which names types in the
+// errors config.Load reports.
+func reflectName(t types.Type) string {
+	switch t := types.Unalias(t).(type) {
+	case *types.Basic:
+		return types.Typ[t.Kind()].Name()
+	case *types.Pointer:
+		return "*" + reflectName(t.Elem())
+	case *types.Slice:
+		return "[]" + reflectName(t.Elem())
+	case *types.Map:
+		return "map[" + reflectName(t.Key()) + "]" + reflectName(t.Elem())
+	case *types.Named:
+		obj := t.Obj()
+		name := obj.Name()
+		if obj.Pkg() != nil {
+			name = obj.Pkg().Name() + "." + name
+		}
+		if args := t.TypeArgs(); args != nil {
+			spelled := make([]string, args.Len())
+			for i := range spelled {
+				spelled[i] = types.TypeString(args.At(i), func(pkg *types.Package) string {
+					return pkg.Path()
+				})
+			}
+			name += "[" + strings.Join(spelled, ",") + "]"
+		}
+		return name
+	}
+	return types.TypeString(t, nil)
+}
@@ -0,0 +1,509 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/stuft2/envchain/config/envchainrt"
+	"github.com/stuft2/envchain/internal/envtypes"
+	"golang.org/x/tools/go/packages"
+)
+
+const (
+	configPath      = "github.com/stuft2/envchain/config"
+	runtimePath     = "github.com/stuft2/envchain/config/envchainrt"
+	generatedSuffix = "_envchain.go"
+	generatedHeader = "// Code generated by envchain-gen; DO NOT EDIT."
+)
+
+func isGeneratedFile(groups []*ast.CommentGroup) bool {
+	for _, group := range groups {
+		for _, comment := range group.List {
+			if comment.Text == generatedHeader {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// generate returns the formatted source of the loaders for opts.types in pkg.
+// Every invalid tag and unsupported field type is reported, each with its position.
+func generate(pkg *packages.Package, opts options) ([]byte, error) {
+	g := &generator{
+		pkg:     pkg,
+		opts:    opts,
+		imports: map[string]string{configPath: "config", runtimePath: "envchainrt"},
+	}
+
+	for _, name := range opts.types {
+		g.generateType(name)
+	}
+	if len(g.errs) > 0 {
+		return nil, errors.Join(g.errs...)
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "%s\n\npackage %s\n\n", generatedHeader, pkg.Name)
+	src.WriteString("import (\n")
+	paths := slices.Sorted(maps.Keys(g.imports))
+	// Standard library packages come first, as goimports groups them.
+	slices.SortStableFunc(paths, func(a, b string) int {
+		return compareBool(isStdlib(b), isStdlib(a))
+	})
+	for i, path := range paths {
+		if i > 0 && isStdlib(paths[i-1]) && !isStdlib(path) {
+			src.WriteString("\n")
+		}
+		name := g.imports[path]
+		if name == lastElement(path) {
+			fmt.Fprintf(&src, "\t%q\n", path)
+		} else {
+			fmt.Fprintf(&src, "\t%s %q\n", name, path)
+		}
+	}
+	src.WriteString(")\n")
+	src.Write(g.out.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+type generator struct {
+	pkg     *packages.Package
+	opts    options
+	imports map[string]string
+	errs    []error
+	out     bytes.Buffer
+
+	// State of the type being generated.
+	typeName string
+	fields   []string
+	keySets  [][]string
+	body     bytes.Buffer
+	vars     int
+	visiting []types.Type
+	// decoders names the decoding functions emitted into funcs, by the type,
+	// format and layout they decode.
+	decoders map[string]string
+	funcs    bytes.Buffer
+}
+
+func (g *generator) errorf(pos interface{ Pos() token.Pos }, format string, args ...any) {
+	g.errs = append(g.errs, fmt.Errorf("%s: %s", g.pkg.Fset.Position(pos.Pos()), fmt.Sprintf(format, args...)))
+}
+
+func (g *generator) generateType(name string) {
+	obj := g.pkg.Types.Scope().Lookup(name)
+	if obj == nil {
+		g.errs = append(g.errs, fmt.Errorf("type %s not found in package %s", name, g.pkg.PkgPath))
+		return
+	}
+	typeName, ok := obj.(*types.TypeName)
+	if !ok {
+		g.errs = append(g.errs, fmt.Errorf("%s is not a type", name))
+		return
+	}
+	st, ok := typeName.Type().Underlying().(*types.Struct)
+	if !ok {
+		g.errorf(typeName, "%s is not a struct type", name)
+		return
+	}
+
+	g.typeName = name
+	g.fields = nil
+	g.keySets = nil
+	g.body.Reset()
+	g.vars = 0
+	g.visiting = []types.Type{typeName.Type()}
+	g.decoders = map[string]string{}
+	g.funcs.Reset()
+
+	g.structFields(1, "st", "target", st, g.opts.auto, nil, "")
+
+	fmt.Fprintf(&g.out, "\nvar %s = [...]*envchainrt.Field{\n", g.fieldsVar())
+	for _, field := range g.fields {
+		fmt.Fprintf(&g.out, "\t%s,\n", field)
+	}
+	g.out.WriteString("}\n")
+	if len(g.keySets) > 0 {
+		fmt.Fprintf(&g.out, "\nvar %s = [...][]string{\n", g.keysVar())
+		for _, keys := range g.keySets {
+			quoted := make([]string, len(keys))
+			for i, key := range keys {
+				quoted[i] = strconv.Quote(key)
+			}
+			fmt.Fprintf(&g.out, "\t{%s},\n", strings.Join(quoted, ", "))
+		}
+		g.out.WriteString("}\n")
+	}
+
+	funcName := "Load" + upperFirst(name)
+	if !ast.IsExported(name) {
+		funcName = "load" + upperFirst(name)
+	}
+	fmt.Fprintf(&g.out, "\n// %s populates target from source with the same rules as config.LoadFrom.\n", funcName)
+	g.out.WriteString("// A nil source reads the process environment.\n")
+	fmt.Fprintf(&g.out, "func %s(target *%s, source config.Lookuper, opts ...config.Option) error {\n", funcName, name)
+	fmt.Fprintf(&g.out, "\ts := envchainrt.NewSession(source, %t, opts...)\n", g.opts.auto)
+	g.out.WriteString("\tst := s.Root()\n")
+	g.out.Write(g.body.Bytes())
+	g.out.WriteString("\tst.Done(target)\n")
+	g.out.WriteString("\treturn s.Err()\n")
+	g.out.WriteString("}\n")
+	g.out.Write(g.funcs.Bytes())
+}
+
+// structFields emits the statements that load the fields of st, a struct whose
+// value is addressed by target, through the envchainrt.Struct variable stVar. When
+// keys is not nil, the keys of value fields, relative to the enclosing collection
+// element, are appended to it.
+func (g *generator) structFields(depth int, stVar, target string, st *types.Struct, auto bool, keys *[]string, keyPrefix string) {
+	type compiledField struct {
+		index int
+		field *types.Var
+		shape envchainrt.FieldShape
+		info  envchainrt.FieldInfo
+	}
+	var fields []compiledField
+	// declared holds the keys of the value fields, to turn off KEY_FILE companions
+	// that another field declares, as config.Load does.
+	declared := map[string]bool{}
+	for i := 0; i < st.NumFields(); i++ {
+		field := st.Field(i)
+		if !field.Exported() {
+			continue
+		}
+
+		shape := envtypes.Shape(field.Type())
+		compiled := envchainrt.CompileField(field.Name(), reflect.StructTag(st.Tag(i)), field.Embedded(), shape, auto)
+		if err := compiled.Err(); err != nil {
+			g.errorf(field, "field %s: %v", field.Name(), err)
+			continue
+		}
+
+		info := compiled.Info()
+		if info.Kind == envchainrt.FieldIgnored {
+			continue
+		}
+		fields = append(fields, compiledField{index: i, field: field, shape: shape, info: info})
+		if info.Kind == envchainrt.FieldValue {
+			declared[info.Tag.Key] = true
+			for _, alias := range info.Tag.Aliases {
+				declared[alias] = true
+			}
+		}
+	}
+
+	for _, compiled := range fields {
+		field, info := compiled.field, compiled.info
+		noFileKey := info.Kind == envchainrt.FieldValue && declared[info.Tag.Key+"_FILE"]
+		ref := g.addField(field.Name(), st.Tag(compiled.index), field.Embedded(), compiled.shape, auto, noFileKey)
+		access := target + "." + field.Name()
+
+		switch info.Kind {
+		case envchainrt.FieldValue:
+			if err := envtypes.CheckValue(field.Type(), info.Tag.Format, info.Tag.Layout, g.opts.parsers); err != nil {
+				g.errorf(field, "field %s: %v", field.Name(), err)
+				continue
+			}
+			g.value(depth, stVar, access, ref, field, info)
+			if keys != nil {
+				key := keyPrefix + info.Tag.Key
+				*keys = append(*keys, key, key+"_FILE")
+				for _, alias := range info.Tag.Aliases {
+					*keys = append(*keys, keyPrefix+alias)
+				}
+			}
+		case envchainrt.FieldNested:
+			g.nested(depth, stVar, access, ref, field, info, keys, keyPrefix)
+		case envchainrt.FieldCollection:
+			g.collection(depth, stVar, access, ref, field, info)
+		}
+	}
+}
+
+func (g *generator) nested(depth int, stVar, access, ref string, field *types.Var, info envchainrt.FieldInfo, keys *[]string, keyPrefix string) {
+	fieldType := field.Type()
+	pointer, isPointer := fieldType.Underlying().(*types.Pointer)
+	structType := fieldType
+	if isPointer {
+		structType = pointer.Elem()
+	}
+	if !g.enter(field, structType) {
+		return
+	}
+	defer g.leave()
+
+	child := g.newVar("st")
+	st := structType.Underlying().(*types.Struct)
+	g.line(depth, "{")
+	if !isPointer {
+		g.line(depth+1, "%s := %s.Nested(%s, false)", child, stVar, ref)
+		g.structFields(depth+1, child, access, st, info.Auto, keys, keyPrefix+info.Prefix)
+		g.line(depth+1, "%s.Done(&%s)", child, access)
+		g.line(depth, "}")
+		return
+	}
+
+	typeName, ok := g.typeString(field, structType)
+	if !ok {
+		return
+	}
+	value := g.newVar("v")
+	g.line(depth+1, "%s := %s", value, access)
+	g.line(depth+1, "%s := %s.Nested(%s, %s == nil)", child, stVar, ref, value)
+	g.line(depth+1, "if %s == nil {", value)
+	g.line(depth+2, "%s = new(%s)", value, typeName)
+	g.line(depth+1, "}")
+	g.structFields(depth+1, child, value, st, info.Auto, keys, keyPrefix+info.Prefix)
+	g.line(depth+1, "if %s.Done(%s) && %s == nil {", child, value, access)
+	g.line(depth+2, "%s = %s", access, value)
+	g.line(depth+1, "}")
+	g.line(depth, "}")
+}
+
+func (g *generator) collection(depth int, stVar, access, ref string, field *types.Var, info envchainrt.FieldInfo) {
+	fieldType := field.Type()
+	var elemType types.Type
+	slice := false
+	switch t := fieldType.Underlying().(type) {
+	case *types.Slice:
+		elemType, slice = t.Elem(), true
+	case *types.Map:
+		elemType = t.Elem()
+	}
+	pointer, isPointer := elemType.Underlying().(*types.Pointer)
+	structType := elemType
+	if isPointer {
+		structType = pointer.Elem()
+	}
+	if !g.enter(field, structType) {
+		return
+	}
+	defer g.leave()
+
+	collectionTypeName, ok := g.typeString(field, fieldType)
+	if !ok {
+		return
+	}
+	structTypeName, ok := g.typeString(field, structType)
+	if !ok {
+		return
+	}
+
+	keySet := len(g.keySets)
+	g.keySets = append(g.keySets, nil)
+	var keys []string
+
+	c, ids, values, id, child, value := g.newVar("c"), g.newVar("ids"), g.newVar("values"), g.newVar("id"), g.newVar("st"), g.newVar("v")
+	keysRef := fmt.Sprintf("%s[%d]", g.keysVar(), keySet)
+	g.line(depth, "if %s, %s := %s.Collection(%s, %t, %s, %s != nil); len(%s) > 0 {", c, ids, stVar, ref, slice, keysRef, access, ids)
+	g.line(depth+1, "%s := make(%s, len(%s))", values, collectionTypeName, ids)
+	if slice {
+		index := g.newVar("i")
+		g.line(depth+1, "for %s, %s := range %s {", index, id, ids)
+		g.line(depth+2, "%s := %s.Element(%s)", child, c, id)
+		if isPointer {
+			g.line(depth+2, "%s := new(%s)", value, structTypeName)
+			g.line(depth+2, "%s[%s] = %s", values, index, value)
+		} else {
+			g.line(depth+2, "%s := &%s[%s]", value, values, index)
+		}
+	} else {
+		g.line(depth+1, "for _, %s := range %s {", id, ids)
+		g.line(depth+2, "%s := %s.Element(%s)", child, c, id)
+		g.line(depth+2, "%s := new(%s)", value, structTypeName)
+	}
+
+	g.structFields(depth+2, child, value, structType.Underlying().(*types.Struct), info.Auto, &keys, "")
+	g.line(depth+2, "%s.Done(%s)", child, value)
+
+	if !slice {
+		key := id
+		keyType := fieldType.Underlying().(*types.Map).Key()
+		if !types.Identical(keyType, types.Typ[types.String]) {
+			keyTypeName, ok := g.typeString(field, keyType)
+			if !ok {
+				return
+			}
+			key = keyTypeName + "(" + id + ")"
+		}
+		element := "*" + value
+		if isPointer {
+			element = value
+		}
+		g.line(depth+2, "%s[%s] = %s", values, key, element)
+	}
+	g.line(depth+1, "}")
+	g.line(depth+1, "%s = %s", access, values)
+	g.line(depth, "}")
+
+	g.keySets[keySet] = keys
+}
+
+// enter guards against recursive struct types, which Load cannot populate either.
+func (g *generator) enter(field *types.Var, t types.Type) bool {
+	for _, seen := range g.visiting {
+		if types.Identical(seen, t) {
+			g.errorf(field, "field %s: recursive struct type %s is not supported", field.Name(), types.TypeString(t, g.qualifier))
+			return false
+		}
+	}
+	g.visiting = append(g.visiting, t)
+	return true
+}
+
+func (g *generator) leave() {
+	g.visiting = g.visiting[:len(g.visiting)-1]
+}
+
+// addField records the compiled field declaration and returns an expression for it.
+func (g *generator) addField(name, tag string, embedded bool, shape envchainrt.FieldShape, auto, noFileKey bool) string {
+	shapeName := map[envchainrt.FieldShape]string{
+		envchainrt.ShapeValue:      "envchainrt.ShapeValue",
+		envchainrt.ShapeStruct:     "envchainrt.ShapeStruct",
+		envchainrt.ShapeCollection: "envchainrt.ShapeCollection",
+	}[shape]
+	decl := fmt.Sprintf("envchainrt.CompileField(%q, %s, %t, %s, %t)", name, quoteTag(tag), embedded, shapeName, auto)
+	if noFileKey {
+		decl += ".WithoutFileKey()"
+	}
+	g.fields = append(g.fields, decl)
+	return fmt.Sprintf("%s[%d]", g.fieldsVar(), len(g.fields)-1)
+}
+
+// fieldsVar and keysVar name the package-level variables holding the compiled
+// fields and the collection element keys of the type being generated.
+func (g *generator) fieldsVar() string {
+	return "envchain" + upperFirst(g.typeName) + "Fields"
+}
+
+func (g *generator) keysVar() string {
+	return "envchain" + upperFirst(g.typeName) + "ElementKeys"
+}
+
+// typeString spells t in the generated file, reporting types that cannot be named
+// outside their package.
+func (g *generator) typeString(field *types.Var, t types.Type) (string, bool) {
+	if named := unexportedForeign(t, g.pkg.Types); named != nil {
+		g.errorf(field, "field %s: type %s is not exported from package %s", field.Name(), named.Obj().Name(), named.Obj().Pkg().Path())
+		return "", false
+	}
+	return types.TypeString(t, g.qualifier), true
+}
+
+func unexportedForeign(t types.Type, pkg *types.Package) *types.Named {
+	switch t := t.(type) {
+	case *types.Named:
+		if obj := t.Obj(); obj.Pkg() != nil && obj.Pkg() != pkg && !obj.Exported() {
+			return t
+		}
+		if args := t.TypeArgs(); args != nil {
+			for i := 0; i < args.Len(); i++ {
+				if named := unexportedForeign(args.At(i), pkg); named != nil {
+					return named
+				}
+			}
+		}
+	case *types.Pointer:
+		return unexportedForeign(t.Elem(), pkg)
+	case *types.Slice:
+		return unexportedForeign(t.Elem(), pkg)
+	case *types.Map:
+		if named := unexportedForeign(t.Key(), pkg); named != nil {
+			return named
+		}
+		return unexportedForeign(t.Elem(), pkg)
+	}
+	return nil
+}
+
+// qualifier names packages in generated code, adding imports as needed.
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg.Types {
+		return ""
+	}
+	if name, ok := g.imports[pkg.Path()]; ok {
+		return name
+	}
+
+	name := pkg.Name()
+	for i := 2; g.importNameTaken(name); i++ {
+		name = pkg.Name() + strconv.Itoa(i)
+	}
+	g.imports[pkg.Path()] = name
+	return name
+}
+
+func (g *generator) importNameTaken(name string) bool {
+	for _, taken := range g.imports {
+		if taken == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) newVar(prefix string) string {
+	g.vars++
+	return prefix + strconv.Itoa(g.vars)
+}
+
+func (g *generator) line(depth int, format string, args ...any) {
+	g.body.WriteString(strings.Repeat("\t", depth))
+	fmt.Fprintf(&g.body, format, args...)
+	g.body.WriteByte('\n')
+}
+
+func quoteTag(tag string) string {
+	if strconv.CanBackquote(tag) {
+		return "`" + tag + "`"
+	}
+	return strconv.Quote(tag)
+}
+
+func upperFirst(s string) string {
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return s
+	}
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// isStdlib reports whether path names a standard library package, whose first
+// element has no dot.
+func isStdlib(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func lastElement(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
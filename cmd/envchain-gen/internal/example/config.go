@@ -0,0 +1,93 @@
+// Package example holds a config struct covering the field kinds envchain-gen
+// supports. Its generated loader is checked against config.LoadFrom.
+package example
+
+import (
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/stuft2/envchain/config"
+)
+
+//go:generate go run github.com/stuft2/envchain/cmd/envchain-gen -type Config
+
+type Config struct {
+	Name      string               `env:"NAME,required"`
+	Port      int                  `env:"PORT,default=8080,min=1,max=65535"`
+	Debug     bool                 `env:"DEBUG"`
+	Mode      string               `env:"MODE,default=dev,oneof=dev|prod"`
+	Timeout   time.Duration        `env:"TIMEOUT,default=5s"`
+	Started   time.Time            `env:"STARTED,layout=2006-01-02"`
+	Endpoint  *url.URL             `env:"ENDPOINT"`
+	Tags      []string             `env:"TAGS"`
+	Limits    map[string]int       `env:"LIMITS"`
+	Labels    map[string]string    `env:"LABELS,format=json"`
+	Key       []byte               `env:"KEY,format=hex,secret"`
+	Level     Level                `env:"LEVEL,default=info"`
+	Retries   config.Optional[int] `env:"RETRIES"`
+	Token     string               `env:"TOKEN,group=auth,exactlyone,secret"`
+	TokenFile string               `env:"TOKEN_PATH,group=auth,exactlyone"`
+	Ratio     float64              `env:"RATIO,max=1"`
+	MaxBytes  int64                `env:"MAX_BYTES,format=bytes,default=1MiB"`
+	Workers   *int                 `env:"WORKERS,min=1"`
+	Weights   []float32            `env:"WEIGHTS,sep=;"`
+	Salt      string               `env:"SALT,format=base64"`
+	Zones     map[Zone]uint16      `env:"ZONES"`
+
+	Database Database           `envPrefix:"DB_"`
+	Cache    *Cache             `envPrefix:"CACHE_"`
+	Upstream []Upstream         `envPrefix:"UPSTREAM_"`
+	Regions  map[string]*Region `envPrefix:"REGION_"`
+
+	internal string
+	Ignored  string `env:"-"`
+}
+
+type Database struct {
+	Host string `env:"HOST,default=localhost"`
+	Port int    `env:"PORT,default=5432"`
+	User string `env:"USER,required"`
+}
+
+func (d Database) Validate() error {
+	if d.Host == "" {
+		return errors.New("host must not be empty")
+	}
+	return nil
+}
+
+type Cache struct {
+	Addr string        `env:"ADDR,required"`
+	TTL  time.Duration `env:"TTL,default=1m"`
+}
+
+type Upstream struct {
+	URL    string `env:"URL,required"`
+	Weight int    `env:"WEIGHT,default=1"`
+}
+
+type Region struct {
+	Endpoint string `env:"ENDPOINT,required"`
+	Replicas uint   `env:"REPLICAS"`
+}
+
+// Zone is a string type without methods of its own.
+type Zone string
+
+// Level decodes itself from text.
+type Level int
+
+func (l *Level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	case "error":
+		*l = 2
+	default:
+		return errors.New("unknown level")
+	}
+	return nil
+}
@@ -0,0 +1,525 @@
+// Code generated by envchain-gen; DO NOT EDIT.
+
+package example
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/stuft2/envchain/config"
+	"github.com/stuft2/envchain/config/envchainrt"
+)
+
+var envchainConfigFields = [...]*envchainrt.Field{
+	envchainrt.CompileField("Name", `env:"NAME,required"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Port", `env:"PORT,default=8080,min=1,max=65535"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Debug", `env:"DEBUG"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Mode", `env:"MODE,default=dev,oneof=dev|prod"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Timeout", `env:"TIMEOUT,default=5s"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Started", `env:"STARTED,layout=2006-01-02"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Endpoint", `env:"ENDPOINT"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Tags", `env:"TAGS"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Limits", `env:"LIMITS"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Labels", `env:"LABELS,format=json"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Key", `env:"KEY,format=hex,secret"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Level", `env:"LEVEL,default=info"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Retries", `env:"RETRIES"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Token", `env:"TOKEN,group=auth,exactlyone,secret"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("TokenFile", `env:"TOKEN_PATH,group=auth,exactlyone"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Ratio", `env:"RATIO,max=1"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("MaxBytes", `env:"MAX_BYTES,format=bytes,default=1MiB"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Workers", `env:"WORKERS,min=1"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Weights", `env:"WEIGHTS,sep=;"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Salt", `env:"SALT,format=base64"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Zones", `env:"ZONES"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Database", `envPrefix:"DB_"`, false, envchainrt.ShapeStruct, false),
+	envchainrt.CompileField("Host", `env:"HOST,default=localhost"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Port", `env:"PORT,default=5432"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("User", `env:"USER,required"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Cache", `envPrefix:"CACHE_"`, false, envchainrt.ShapeStruct, false),
+	envchainrt.CompileField("Addr", `env:"ADDR,required"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("TTL", `env:"TTL,default=1m"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Upstream", `envPrefix:"UPSTREAM_"`, false, envchainrt.ShapeCollection, false),
+	envchainrt.CompileField("URL", `env:"URL,required"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Weight", `env:"WEIGHT,default=1"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Regions", `envPrefix:"REGION_"`, false, envchainrt.ShapeCollection, false),
+	envchainrt.CompileField("Endpoint", `env:"ENDPOINT,required"`, false, envchainrt.ShapeValue, false),
+	envchainrt.CompileField("Replicas", `env:"REPLICAS"`, false, envchainrt.ShapeValue, false),
+}
+
+var envchainConfigElementKeys = [...][]string{
+	{"URL", "URL_FILE", "WEIGHT", "WEIGHT_FILE"},
+	{"ENDPOINT", "ENDPOINT_FILE", "REPLICAS", "REPLICAS_FILE"},
+}
+
+// LoadConfig populates target from source with the same rules as config.LoadFrom.
+// A nil source reads the process environment.
+func LoadConfig(target *Config, source config.Lookuper, opts ...config.Option) error {
+	s := envchainrt.NewSession(source, false, opts...)
+	st := s.Root()
+	switch raw, action := st.Lookup(envchainConfigFields[0], target.Name == ""); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode0(envchainConfigFields[0], raw); st.Decoded("string", err) {
+			target.Name = v
+		}
+	case envchainrt.Keep:
+		st.Keep(target.Name)
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[1], target.Port == 0); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode1(envchainConfigFields[1], raw); st.Decoded("int", err) {
+			target.Port = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.Port))
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[2], !target.Debug); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode2(envchainConfigFields[2], raw); st.Decoded("bool", err) {
+			target.Debug = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.Debug))
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[3], target.Mode == ""); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode0(envchainConfigFields[3], raw); st.Decoded("string", err) {
+			target.Mode = v
+		}
+	case envchainrt.Keep:
+		st.Keep(target.Mode)
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[4], target.Timeout == 0); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode3(envchainConfigFields[4], raw); st.Decoded("time.Duration", err) {
+			target.Timeout = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.Timeout))
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[5], target.Started == (time.Time{})); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode4(envchainConfigFields[5], raw); st.Decoded("time.Time", err) {
+			target.Started = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.Started))
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[6], target.Endpoint == nil); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode6(envchainConfigFields[6], raw); st.Decoded("*url.URL", err) {
+			target.Endpoint = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(*target.Endpoint))
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[7], target.Tags == nil); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode7(envchainConfigFields[7], raw); st.Decoded("[]string", err) {
+			target.Tags = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.Tags))
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[8], target.Limits == nil); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode8(envchainConfigFields[8], raw); st.Decoded("map[string]int", err) {
+			target.Limits = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.Limits))
+	}
+	envchainrt.LoadValue(st, envchainConfigFields[9], &target.Labels)
+	switch raw, action := st.Lookup(envchainConfigFields[10], target.Key == nil); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode9(envchainConfigFields[10], raw); st.Decoded("[]uint8", err) {
+			target.Key = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.Key))
+	}
+	envchainrt.LoadValue(st, envchainConfigFields[11], &target.Level)
+	switch raw, action := st.Lookup(envchainConfigFields[12], !target.Retries.IsSet()); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode10(envchainConfigFields[12], raw); st.Decoded("config.Optional[int]", err) {
+			target.Retries = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.Retries))
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[13], target.Token == ""); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode0(envchainConfigFields[13], raw); st.Decoded("string", err) {
+			target.Token = v
+		}
+	case envchainrt.Keep:
+		st.Keep(target.Token)
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[14], target.TokenFile == ""); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode0(envchainConfigFields[14], raw); st.Decoded("string", err) {
+			target.TokenFile = v
+		}
+	case envchainrt.Keep:
+		st.Keep(target.TokenFile)
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[15], target.Ratio == 0); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode11(envchainConfigFields[15], raw); st.Decoded("float64", err) {
+			target.Ratio = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.Ratio))
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[16], target.MaxBytes == 0); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode12(envchainConfigFields[16], raw); st.Decoded("int64", err) {
+			target.MaxBytes = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.MaxBytes))
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[17], target.Workers == nil); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode13(envchainConfigFields[17], raw); st.Decoded("*int", err) {
+			target.Workers = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(*target.Workers))
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[18], target.Weights == nil); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode15(envchainConfigFields[18], raw); st.Decoded("[]float32", err) {
+			target.Weights = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.Weights))
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[19], target.Salt == ""); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode16(envchainConfigFields[19], raw); st.Decoded("string", err) {
+			target.Salt = v
+		}
+	case envchainrt.Keep:
+		st.Keep(target.Salt)
+	}
+	switch raw, action := st.Lookup(envchainConfigFields[20], target.Zones == nil); action {
+	case envchainrt.Decode:
+		if v, err := envchainConfigDecode19(envchainConfigFields[20], raw); st.Decoded("map[example.Zone]uint16", err) {
+			target.Zones = v
+		}
+	case envchainrt.Keep:
+		st.Keep(fmt.Sprint(target.Zones))
+	}
+	{
+		st1 := st.Nested(envchainConfigFields[21], false)
+		switch raw, action := st1.Lookup(envchainConfigFields[22], target.Database.Host == ""); action {
+		case envchainrt.Decode:
+			if v, err := envchainConfigDecode0(envchainConfigFields[22], raw); st1.Decoded("string", err) {
+				target.Database.Host = v
+			}
+		case envchainrt.Keep:
+			st1.Keep(target.Database.Host)
+		}
+		switch raw, action := st1.Lookup(envchainConfigFields[23], target.Database.Port == 0); action {
+		case envchainrt.Decode:
+			if v, err := envchainConfigDecode1(envchainConfigFields[23], raw); st1.Decoded("int", err) {
+				target.Database.Port = v
+			}
+		case envchainrt.Keep:
+			st1.Keep(fmt.Sprint(target.Database.Port))
+		}
+		switch raw, action := st1.Lookup(envchainConfigFields[24], target.Database.User == ""); action {
+		case envchainrt.Decode:
+			if v, err := envchainConfigDecode0(envchainConfigFields[24], raw); st1.Decoded("string", err) {
+				target.Database.User = v
+			}
+		case envchainrt.Keep:
+			st1.Keep(target.Database.User)
+		}
+		st1.Done(&target.Database)
+	}
+	{
+		v3 := target.Cache
+		st2 := st.Nested(envchainConfigFields[25], v3 == nil)
+		if v3 == nil {
+			v3 = new(Cache)
+		}
+		switch raw, action := st2.Lookup(envchainConfigFields[26], v3.Addr == ""); action {
+		case envchainrt.Decode:
+			if v, err := envchainConfigDecode0(envchainConfigFields[26], raw); st2.Decoded("string", err) {
+				v3.Addr = v
+			}
+		case envchainrt.Keep:
+			st2.Keep(v3.Addr)
+		}
+		switch raw, action := st2.Lookup(envchainConfigFields[27], v3.TTL == 0); action {
+		case envchainrt.Decode:
+			if v, err := envchainConfigDecode3(envchainConfigFields[27], raw); st2.Decoded("time.Duration", err) {
+				v3.TTL = v
+			}
+		case envchainrt.Keep:
+			st2.Keep(fmt.Sprint(v3.TTL))
+		}
+		if st2.Done(v3) && target.Cache == nil {
+			target.Cache = v3
+		}
+	}
+	if c4, ids5 := st.Collection(envchainConfigFields[28], true, envchainConfigElementKeys[0], target.Upstream != nil); len(ids5) > 0 {
+		values6 := make([]Upstream, len(ids5))
+		for i10, id7 := range ids5 {
+			st8 := c4.Element(id7)
+			v9 := &values6[i10]
+			switch raw, action := st8.Lookup(envchainConfigFields[29], v9.URL == ""); action {
+			case envchainrt.Decode:
+				if v, err := envchainConfigDecode0(envchainConfigFields[29], raw); st8.Decoded("string", err) {
+					v9.URL = v
+				}
+			case envchainrt.Keep:
+				st8.Keep(v9.URL)
+			}
+			switch raw, action := st8.Lookup(envchainConfigFields[30], v9.Weight == 0); action {
+			case envchainrt.Decode:
+				if v, err := envchainConfigDecode1(envchainConfigFields[30], raw); st8.Decoded("int", err) {
+					v9.Weight = v
+				}
+			case envchainrt.Keep:
+				st8.Keep(fmt.Sprint(v9.Weight))
+			}
+			st8.Done(v9)
+		}
+		target.Upstream = values6
+	}
+	if c11, ids12 := st.Collection(envchainConfigFields[31], false, envchainConfigElementKeys[1], target.Regions != nil); len(ids12) > 0 {
+		values13 := make(map[string]*Region, len(ids12))
+		for _, id14 := range ids12 {
+			st15 := c11.Element(id14)
+			v16 := new(Region)
+			switch raw, action := st15.Lookup(envchainConfigFields[32], v16.Endpoint == ""); action {
+			case envchainrt.Decode:
+				if v, err := envchainConfigDecode0(envchainConfigFields[32], raw); st15.Decoded("string", err) {
+					v16.Endpoint = v
+				}
+			case envchainrt.Keep:
+				st15.Keep(v16.Endpoint)
+			}
+			switch raw, action := st15.Lookup(envchainConfigFields[33], v16.Replicas == 0); action {
+			case envchainrt.Decode:
+				if v, err := envchainConfigDecode20(envchainConfigFields[33], raw); st15.Decoded("uint", err) {
+					v16.Replicas = v
+				}
+			case envchainrt.Keep:
+				st15.Keep(fmt.Sprint(v16.Replicas))
+			}
+			st15.Done(v16)
+			values13[id14] = v16
+		}
+		target.Regions = values13
+	}
+	st.Done(target)
+	return s.Err()
+}
+
+func envchainConfigDecode0(f *envchainrt.Field, raw string) (v string, err error) {
+	return raw, f.CheckString(raw)
+}
+
+func envchainConfigDecode1(f *envchainrt.Field, raw string) (v int, err error) {
+	n, err := strconv.ParseInt(raw, 10, strconv.IntSize)
+	if err != nil {
+		return v, err
+	}
+	return int(n), f.CheckInt(n, "int")
+}
+
+func envchainConfigDecode2(f *envchainrt.Field, raw string) (v bool, err error) {
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return v, err
+	}
+	return b, f.CheckOther("bool")
+}
+
+func envchainConfigDecode3(f *envchainrt.Field, raw string) (v time.Duration, err error) {
+	v, err = time.ParseDuration(raw)
+	if err != nil {
+		return v, err
+	}
+	return v, f.CheckDuration(v, "time.Duration")
+}
+
+func envchainConfigDecode4(f *envchainrt.Field, raw string) (v time.Time, err error) {
+	v, err = time.Parse("2006-01-02", raw)
+	if err != nil {
+		return v, err
+	}
+	return v, f.CheckOther("time.Time")
+}
+
+func envchainConfigDecode5(f *envchainrt.Field, raw string) (v url.URL, err error) {
+	u, err := envchainrt.ParseURL(raw)
+	if err != nil {
+		return v, err
+	}
+	return *u, f.CheckOther("url.URL")
+}
+
+func envchainConfigDecode6(f *envchainrt.Field, raw string) (v *url.URL, err error) {
+	e, err := envchainConfigDecode5(f, raw)
+	if err != nil {
+		return v, err
+	}
+	return &e, nil
+}
+
+func envchainConfigDecode7(f *envchainrt.Field, raw string) (v []string, err error) {
+	parts, err := f.Split(raw)
+	if err != nil {
+		return v, err
+	}
+	elem := f.Element()
+	v = make([]string, len(parts))
+	for i, part := range parts {
+		if v[i], err = envchainConfigDecode0(elem, part); err != nil {
+			return v, envchainrt.ElementError(err, "element %d", i)
+		}
+	}
+	return v, f.CheckCollection(len(v), true, "[]string")
+}
+
+func envchainConfigDecode8(f *envchainrt.Field, raw string) (v map[string]int, err error) {
+	entries, err := f.SplitMap(raw)
+	if err != nil {
+		return v, err
+	}
+	keyField, elemField := f.MapKey(), f.Element()
+	v = make(map[string]int, len(entries))
+	for _, entry := range slices.Sorted(maps.Keys(entries)) {
+		k, err := envchainConfigDecode0(keyField, entry)
+		if err != nil {
+			return v, envchainrt.ElementError(err, "key %q", entry)
+		}
+		e, err := envchainConfigDecode1(elemField, entries[entry])
+		if err != nil {
+			return v, envchainrt.ElementError(err, "key %q", entry)
+		}
+		v[k] = e
+	}
+	return v, f.CheckCollection(len(v), false, "map[string]int")
+}
+
+func envchainConfigDecode9(f *envchainrt.Field, raw string) (v []byte, err error) {
+	b, err := f.DecodeBinary(raw)
+	if err != nil {
+		return v, err
+	}
+	return b, f.CheckCollection(len(b), false, "[]uint8")
+}
+
+func envchainConfigDecode10(f *envchainrt.Field, raw string) (v config.Optional[int], err error) {
+	e, err := envchainConfigDecode1(f, raw)
+	if err != nil {
+		return v, err
+	}
+	return config.Some(e), nil
+}
+
+func envchainConfigDecode11(f *envchainrt.Field, raw string) (v float64, err error) {
+	n, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return v, err
+	}
+	return n, f.CheckFloat(n, "float64")
+}
+
+func envchainConfigDecode12(f *envchainrt.Field, raw string) (v int64, err error) {
+	n, err := f.ParseInt(raw, 64)
+	if err != nil {
+		return v, err
+	}
+	return n, f.CheckInt(n, "int64")
+}
+
+func envchainConfigDecode13(f *envchainrt.Field, raw string) (v *int, err error) {
+	e, err := envchainConfigDecode1(f, raw)
+	if err != nil {
+		return v, err
+	}
+	return &e, nil
+}
+
+func envchainConfigDecode14(f *envchainrt.Field, raw string) (v float32, err error) {
+	n, err := strconv.ParseFloat(raw, 32)
+	if err != nil {
+		return v, err
+	}
+	return float32(n), f.CheckFloat(n, "float32")
+}
+
+func envchainConfigDecode15(f *envchainrt.Field, raw string) (v []float32, err error) {
+	parts, err := f.Split(raw)
+	if err != nil {
+		return v, err
+	}
+	elem := f.Element()
+	v = make([]float32, len(parts))
+	for i, part := range parts {
+		if v[i], err = envchainConfigDecode14(elem, part); err != nil {
+			return v, envchainrt.ElementError(err, "element %d", i)
+		}
+	}
+	return v, f.CheckCollection(len(v), false, "[]float32")
+}
+
+func envchainConfigDecode16(f *envchainrt.Field, raw string) (v string, err error) {
+	b, err := f.DecodeBinary(raw)
+	if err != nil {
+		return v, err
+	}
+	return string(b), f.CheckString(string(b))
+}
+
+func envchainConfigDecode17(f *envchainrt.Field, raw string) (v Zone, err error) {
+	return Zone(raw), f.CheckString(raw)
+}
+
+func envchainConfigDecode18(f *envchainrt.Field, raw string) (v uint16, err error) {
+	n, err := strconv.ParseUint(raw, 10, 16)
+	if err != nil {
+		return v, err
+	}
+	return uint16(n), f.CheckUint(n, "uint16")
+}
+
+func envchainConfigDecode19(f *envchainrt.Field, raw string) (v map[Zone]uint16, err error) {
+	entries, err := f.SplitMap(raw)
+	if err != nil {
+		return v, err
+	}
+	keyField, elemField := f.MapKey(), f.Element()
+	v = make(map[Zone]uint16, len(entries))
+	for _, entry := range slices.Sorted(maps.Keys(entries)) {
+		k, err := envchainConfigDecode17(keyField, entry)
+		if err != nil {
+			return v, envchainrt.ElementError(err, "key %q", entry)
+		}
+		e, err := envchainConfigDecode18(elemField, entries[entry])
+		if err != nil {
+			return v, envchainrt.ElementError(err, "key %q", entry)
+		}
+		v[k] = e
+	}
+	return v, f.CheckCollection(len(v), false, "map[example.Zone]uint16")
+}
+
+func envchainConfigDecode20(f *envchainrt.Field, raw string) (v uint, err error) {
+	n, err := strconv.ParseUint(raw, 10, strconv.IntSize)
+	if err != nil {
+		return v, err
+	}
+	return uint(n), f.CheckUint(n, "uint")
+}
@@ -0,0 +1,166 @@
+package example
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stuft2/envchain/config"
+)
+
+// valid sets every field of Config, and the keys of a few under the APP_ prefix.
+var valid = config.MapSource{
+	"NAME":               "api",
+	"PORT":               "9090",
+	"DEBUG":              "true",
+	"MODE":               "prod",
+	"TIMEOUT":            "2s",
+	"STARTED":            "2024-05-01",
+	"ENDPOINT":           "https://example.com/v1",
+	"TAGS":               "a,b",
+	"LIMITS":             "read=10,write=5",
+	"LABELS":             `{"team":"core"}`,
+	"KEY":                "deadbeef",
+	"LEVEL":              "error",
+	"RETRIES":            "3",
+	"TOKEN":              "secret",
+	"RATIO":              "0.5",
+	"MAX_BYTES":          "2MiB",
+	"WORKERS":            "4",
+	"WEIGHTS":            "0.5;1.5",
+	"SALT":               "c2FsdA==",
+	"ZONES":              "a=1,b=2",
+	"DB_USER":            "admin",
+	"CACHE_ADDR":         "localhost:6379",
+	"UPSTREAM_0_URL":     "http://a",
+	"UPSTREAM_1_URL":     "http://b",
+	"UPSTREAM_1_WEIGHT":  "3",
+	"REGION_EU_ENDPOINT": "https://eu.example.com",
+	"REGION_US_ENDPOINT": "https://us.example.com",
+	"REGION_US_REPLICAS": "2",
+	"APP_NAME":           "prefixed",
+	"APP_TOKEN":          "secret",
+	"APP_DB_USER":        "admin",
+	"APP_UNKNOWN":        "x",
+}
+
+// TestLoadConfigMatchesLoad checks that the generated loader produces the same
+// values and errors as config.LoadFrom.
+func TestLoadConfigMatchesLoad(t *testing.T) {
+	tests := []struct {
+		name   string
+		source config.MapSource
+		opts   []config.Option
+		preset func() Config
+	}{
+		{name: "valid", source: valid},
+		{name: "empty", source: config.MapSource{}},
+		{
+			name: "invalid values",
+			source: config.MapSource{
+				"NAME":              "api",
+				"PORT":              "0",
+				"MODE":              "staging",
+				"STARTED":           "May 1",
+				"KEY":               "xyz",
+				"LEVEL":             "loud",
+				"TOKEN":             "a",
+				"TOKEN_PATH":        "/run/token",
+				"RATIO":             "2",
+				"MAX_BYTES":         "1XB",
+				"WORKERS":           "0",
+				"WEIGHTS":           "1;x",
+				"SALT":              "!",
+				"ZONES":             "a=70000",
+				"DB_HOST":           "",
+				"DB_USER":           "admin",
+				"CACHE_TTL":         "1h",
+				"UPSTREAM_0_WEIGHT": "x",
+			},
+		},
+		{name: "prefix and strict", source: valid, opts: []config.Option{config.WithPrefix("APP_"), config.WithStrict("APP_")}},
+		{name: "redacted", source: config.MapSource{"NAME": "api", "KEY": "secret-value"}, opts: []config.Option{config.WithRedactedValues()}},
+		{name: "fill zero", source: valid, opts: []config.Option{config.WithFillZero(), config.WithStrict("")}, preset: preset},
+		{name: "env override", source: valid, opts: []config.Option{config.WithEnvOverride()}, preset: preset},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want, got Config
+			if tt.preset != nil {
+				want, got = tt.preset(), tt.preset()
+			}
+			wantErr := config.LoadFrom(&want, tt.source, tt.opts...)
+			gotErr := LoadConfig(&got, tt.source, tt.opts...)
+
+			if errorString(gotErr) != errorString(wantErr) {
+				t.Fatalf("LoadConfig error = %v\nLoadFrom error  = %v", gotErr, wantErr)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("LoadConfig = %+v\nLoadFrom   = %+v", got, want)
+			}
+		})
+	}
+}
+
+// preset returns a Config as set by flags before the environment is loaded.
+func preset() Config {
+	return Config{
+		Name:     "flag",
+		Port:     9000,
+		Cache:    &Cache{Addr: "flag:6379"},
+		Upstream: []Upstream{{URL: "http://flag"}},
+	}
+}
+
+func TestLoadConfigKeepsExistingPointer(t *testing.T) {
+	cache := &Cache{TTL: time.Hour}
+	cfg := Config{Cache: cache}
+	source := config.MapSource{"NAME": "api", "TOKEN": "t", "DB_USER": "admin", "CACHE_ADDR": "localhost:6379"}
+
+	if err := LoadConfig(&cfg, source); err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+	if cfg.Cache != cache || cache.Addr != "localhost:6379" {
+		t.Fatalf("Cache = %+v, want the existing struct updated", cfg.Cache)
+	}
+}
+
+func TestLoadConfigRejectsAutoKeys(t *testing.T) {
+	var cfg Config
+	err := LoadConfig(&cfg, config.MapSource{}, config.WithAutoKeys())
+	var loadErr *config.LoadError
+	if !errors.As(err, &loadErr) || loadErr.Errors[0].Kind != config.KindTag {
+		t.Fatalf("LoadConfig error = %v, want a tag error for WithAutoKeys", err)
+	}
+}
+
+// BenchmarkLoad compares the generated loader with config.LoadFrom.
+func BenchmarkLoad(b *testing.B) {
+	b.Run("generated", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var cfg Config
+			if err := LoadConfig(&cfg, valid); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("reflection", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var cfg Config
+			if err := config.LoadFrom(&cfg, valid); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
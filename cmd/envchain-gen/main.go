@@ -0,0 +1,192 @@
+// Command envchain-gen generates loaders for config structs that apply the same
+// env tag rules as config.Load. The loaders address each field directly instead
+// of walking the struct and parse values of built-in types, and slices and maps of
+// them, with generated code. Everything else, from lookups to errors, is shared
+// with config.Load through package config/envchainrt, which also decodes the
+// fields config.Load decodes with reflection, such as types that implement
+// encoding.TextUnmarshaler. Tags are checked at generation time, so
+// invalid tags and unsupported field types fail the build instead of surfacing
+// when the program starts.
+//
+// Typical use is a go:generate directive beside the struct:
+//
+//	//go:generate go run github.com/stuft2/envchain/cmd/envchain-gen -type Config
+//
+// For each type T, the generated file declares
+//
+//	func LoadT(target *T, source config.Lookuper, opts ...config.Option) error
+//
+// (loadT for unexported types), which behaves like config.LoadFrom.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
+
+func run(args []string, stderr io.Writer) int {
+	fs := flag.NewFlagSet("envchain-gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	typeNames := fs.String("type", "", "comma-separated list of struct type names (required)")
+	output := fs.String("output", "", "output file name (default <type>_envchain.go in the package directory)")
+	auto := fs.Bool("auto", false, "derive missing keys from field names, as config.WithAutoKeys does")
+	parsers := fs.String("parsers", "", "comma-separated list of types with parsers registered at run time, such as *regexp.Regexp; the loader consults parsers for these types only")
+
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: envchain-gen -type T[,U...] [flags] [package]")
+		fmt.Fprintln(fs.Output())
+		fmt.Fprintln(fs.Output(), "Flags:")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *typeNames == "" || fs.NArg() > 1 {
+		fs.Usage()
+		return 2
+	}
+
+	pattern := "."
+	if fs.NArg() == 1 {
+		pattern = fs.Arg(0)
+	}
+
+	opts := options{
+		types:   splitList(*typeNames),
+		output:  *output,
+		auto:    *auto,
+		parsers: map[string]bool{},
+	}
+	for _, name := range splitList(*parsers) {
+		opts.parsers[name] = true
+	}
+
+	if err := generateFile(pattern, opts); err != nil {
+		fmt.Fprintf(stderr, "envchain-gen: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// options configures one run of the generator.
+type options struct {
+	types   []string
+	output  string
+	auto    bool
+	parsers map[string]bool
+}
+
+// generateFile loads the package matching pattern, generates loaders for the
+// requested types and writes them to the output file.
+func generateFile(pattern string, opts options) error {
+	pkg, err := loadPackage(pattern, opts.output)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkg, opts)
+	if err != nil {
+		return err
+	}
+
+	output := opts.output
+	if output == "" {
+		output = defaultOutput(pkg, opts.types[0])
+	}
+	return os.WriteFile(output, src, 0o644)
+}
+
+// loadPackage type-checks the package matching pattern. A previously generated
+// output file is replaced by its package clause, so a stale loader cannot prevent
+// its own regeneration.
+func loadPackage(pattern, output string) (*packages.Package, error) {
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax,
+	}
+
+	probe, err := packages.Load(&packages.Config{Mode: packages.NeedName | packages.NeedFiles}, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(probe) != 1 {
+		return nil, fmt.Errorf("pattern %q matched %d packages, want 1", pattern, len(probe))
+	}
+	cfg.Overlay = stubGenerated(probe[0], output)
+
+	pkgs, err := packages.Load(cfg, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("pattern %q matched %d packages, want 1", pattern, len(pkgs))
+	}
+	pkg := pkgs[0]
+	if len(pkg.Errors) > 0 {
+		var msgs []string
+		for _, pkgErr := range pkg.Errors {
+			msgs = append(msgs, pkgErr.Error())
+		}
+		return nil, errors.New(strings.Join(msgs, "\n"))
+	}
+	return pkg, nil
+}
+
+// stubGenerated returns an overlay that reduces every generated loader in pkg, or
+// the named output file, to its package clause.
+func stubGenerated(pkg *packages.Package, output string) map[string][]byte {
+	overlay := map[string][]byte{}
+	for _, path := range pkg.GoFiles {
+		isOutput := output != "" && sameFile(path, output)
+		if !isOutput && !strings.HasSuffix(path, generatedSuffix) {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil || !isGeneratedFile(file.Comments) {
+			continue
+		}
+		overlay[path] = []byte("package " + file.Name.Name + "\n")
+	}
+	return overlay
+}
+
+func sameFile(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && absA == absB
+}
+
+func defaultOutput(pkg *packages.Package, typeName string) string {
+	dir := "."
+	if len(pkg.GoFiles) > 0 {
+		dir = filepath.Dir(pkg.GoFiles[0])
+	}
+	return filepath.Join(dir, strings.ToLower(typeName)+generatedSuffix)
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
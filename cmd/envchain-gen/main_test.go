@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedExampleIsUpToDate(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "config_envchain.go")
+	var stderr bytes.Buffer
+	if code := run([]string{"-type", "Config", "-output", output, "./internal/example"}, &stderr); code != 0 {
+		t.Fatalf("run code=%d stderr=%s", code, stderr.String())
+	}
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join("internal", "example", "config_envchain.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("internal/example/config_envchain.go is stale; run go generate ./internal/example")
+	}
+}
+
+func TestRunReportsInvalidFields(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "config_envchain.go")
+	var stderr bytes.Buffer
+	if code := run([]string{"-type", "Config", "-output", output, "./testdata/invalid"}, &stderr); code != 1 {
+		t.Fatalf("run code=%d want 1; stderr=%s", code, stderr.String())
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Fatalf("output written despite errors: %v", err)
+	}
+
+	got := stderr.String()
+	for _, want := range []string{
+		"config.go:10:2: field Port:",
+		"config.go:11:2: field Started: time.Time fields require layout",
+		"config.go:12:2: field Channel: unsupported field type chan int",
+		"config.go:13:2: field Key: format=hex requires a string or []byte field, got int",
+		"config.go:14:2: field Prefixed: envPrefix requires a nested struct",
+		"config.go:15:2: field Retries: unsupported field type complex64",
+		"config.go:16:2: field Self: recursive struct type",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("stderr missing %q; got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Untagged") {
+		t.Errorf("untagged field reported; got:\n%s", got)
+	}
+}
+
+func TestRunLeavesDeclaredFileKeysToTheirFields(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "config_envchain.go")
+	var stderr bytes.Buffer
+	if code := run([]string{"-type", "Config", "-output", output, "./testdata/filekey"}, &stderr); code != 0 {
+		t.Fatalf("run code=%d stderr=%s", code, stderr.String())
+	}
+
+	got, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "envchainrt.CompileField(\"Log\", `env:\"LOG\"`, false, envchainrt.ShapeValue, false).WithoutFileKey()"; !strings.Contains(string(got), want) {
+		t.Fatalf("generated loader missing %s; got:\n%s", want, got)
+	}
+	if strings.Count(string(got), "WithoutFileKey") != 1 {
+		t.Fatalf("expected only Log to turn off its KEY_FILE companion; got:\n%s", got)
+	}
+}
+
+func TestRunRequiresType(t *testing.T) {
+	var stderr bytes.Buffer
+	if code := run(nil, &stderr); code != 2 {
+		t.Fatalf("run code=%d want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "Usage: envchain-gen") {
+		t.Fatalf("usage missing; got %q", stderr.String())
+	}
+}
@@ -0,0 +1,6 @@
+package filekey
+
+type Config struct {
+	Log     string `env:"LOG"`
+	LogFile string `env:"LOG_FILE"`
+}
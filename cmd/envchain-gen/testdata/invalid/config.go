@@ -0,0 +1,19 @@
+package invalid
+
+import (
+	"time"
+
+	"github.com/stuft2/envchain/config"
+)
+
+type Config struct {
+	Port     int                        `env:"PORT,default"`
+	Started  time.Time                  `env:"STARTED"`
+	Channel  chan int                   `env:"CHANNEL"`
+	Key      int                        `env:"KEY,format=hex"`
+	Prefixed string                     `envPrefix:"APP_"`
+	Retries  config.Optional[complex64] `env:"RETRIES"`
+	Self     *Config                    `envPrefix:"SELF_"`
+
+	Untagged chan int
+}
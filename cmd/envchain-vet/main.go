@@ -0,0 +1,15 @@
+// Command envchain-vet reports mistakes in env struct tags that config.Load would
+// only report at run time. Run it on its own or through go vet:
+//
+//	go install github.com/stuft2/envchain/cmd/envchain-vet
+//	go vet -vettool=$(which envchain-vet) ./...
+package main
+
+import (
+	"github.com/stuft2/envchain/analysis/envtag"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(envtag.Analyzer)
+}
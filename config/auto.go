@@ -0,0 +1,56 @@
+package config
+
+import (
+	"strings"
+	"unicode"
+)
+
+// WithAutoKeys derives the key of every field whose tag omits one from the field
+// name, and the prefix of every nested struct without envPrefix from its field
+// name, so DB.MaxConns reads DB_MAX_CONNS. Explicit keys and envPrefix tags still
+// win, `env:"-"` still skips a field, and embedded structs add no prefix.
+func WithAutoKeys() Option {
+	return func(l *loader) {
+		l.auto = true
+	}
+}
+
+// autoTag returns tag with a key derived from name when the tag omits its key and
+// either auto is set or the tag carries the `auto` option.
+func autoTag(tag, name string, auto bool) string {
+	parts := splitTag(tag)
+	if len(parts) > 0 && strings.TrimSpace(parts[0]) != "" {
+		return tag
+	}
+	if !auto {
+		for _, part := range parts {
+			if strings.TrimSpace(part) == "auto" {
+				auto = true
+			}
+		}
+	}
+	if !auto {
+		return tag
+	}
+	return deriveKey(name) + tag
+}
+
+// deriveKey converts a Go field name to SCREAMING_SNAKE_CASE, keeping acronyms
+// together: MaxConns becomes MAX_CONNS, HTTPPort becomes HTTP_PORT and S3Bucket
+// becomes S3_BUCKET.
+func deriveKey(name string) string {
+	runes := []rune(name)
+
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDeriveKey(t *testing.T) {
+	tests := map[string]string{
+		"Port":        "PORT",
+		"MaxConns":    "MAX_CONNS",
+		"HTTPPort":    "HTTP_PORT",
+		"TLSCertFile": "TLS_CERT_FILE",
+		"UserID":      "USER_ID",
+		"S3Bucket":    "S3_BUCKET",
+		"Retries2":    "RETRIES2",
+		"ID":          "ID",
+	}
+
+	for name, want := range tests {
+		if got := deriveKey(name); got != want {
+			t.Fatalf("deriveKey(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLoadDerivesKeysWithAutoKeys(t *testing.T) {
+	type poolConfig struct {
+		MaxConns int           `env:",default=10"`
+		IdleTime time.Duration `env:"IDLE,default=1m"`
+	}
+	type dbConfig struct {
+		Host     string
+		Password string `env:",secret"`
+		Pool     poolConfig
+	}
+	type Common struct {
+		LogLevel string
+	}
+	type testConfig struct {
+		Common
+		HTTPPort  int
+		Name      string `env:"SERVICE_NAME"`
+		Skipped   string `env:"-"`
+		DB        dbConfig
+		Replica   *dbConfig        `envPrefix:"RO_"`
+		Upstreams []upstreamConfig `envPrefix:"UPSTREAMS_"`
+		Regions   map[string]struct {
+			Endpoint string
+		}
+	}
+
+	source := MapSource{
+		"APP_LOG_LEVEL":           "debug",
+		"APP_HTTP_PORT":           "8080",
+		"APP_SERVICE_NAME":        "api",
+		"APP_SKIPPED":             "ignored",
+		"APP_DB_HOST":             "db.internal",
+		"APP_DB_PASSWORD":         "s3cret",
+		"APP_DB_POOL_MAX_CONNS":   "20",
+		"APP_RO_HOST":             "replica.internal",
+		"APP_UPSTREAMS_0_HOST":    "a.internal",
+		"APP_REGIONS_EU_ENDPOINT": "eu.internal",
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source, WithPrefix("APP_"), WithAutoKeys()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.LogLevel != "debug" || cfg.HTTPPort != 8080 || cfg.Name != "api" || cfg.Skipped != "" {
+		t.Fatalf("unexpected root fields: %+v", cfg)
+	}
+	wantDB := dbConfig{Host: "db.internal", Password: "s3cret", Pool: poolConfig{MaxConns: 20, IdleTime: time.Minute}}
+	if cfg.DB != wantDB {
+		t.Fatalf("expected DB %+v, got %+v", wantDB, cfg.DB)
+	}
+	if cfg.Replica == nil || cfg.Replica.Host != "replica.internal" || cfg.Replica.Pool.MaxConns != 10 {
+		t.Fatalf("expected explicit envPrefix to win, got %+v", cfg.Replica)
+	}
+	if len(cfg.Upstreams) != 1 || cfg.Upstreams[0].Host != "a.internal" {
+		t.Fatalf("unexpected upstreams: %+v", cfg.Upstreams)
+	}
+	if cfg.Regions["EU"].Endpoint != "eu.internal" {
+		t.Fatalf("unexpected regions: %+v", cfg.Regions)
+	}
+}
+
+func TestLoadDerivesKeysFromAutoTag(t *testing.T) {
+	type dbConfig struct {
+		MaxConns int
+		Host     string `env:"HOSTNAME"`
+	}
+	type testConfig struct {
+		DB      dbConfig `env:",auto"`
+		Timeout int      `env:",auto,default=30"`
+		Other   dbConfig
+		Manual  string
+	}
+
+	source := MapSource{
+		"DB_MAX_CONNS":    "5",
+		"DB_HOSTNAME":     "db.internal",
+		"MAX_CONNS":       "ignored",
+		"OTHER_MAX_CONNS": "ignored",
+		"MANUAL":          "ignored",
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := testConfig{DB: dbConfig{MaxConns: 5, Host: "db.internal"}, Timeout: 30}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("expected %+v, got %+v", want, cfg)
+	}
+}
+
+func TestDescribeDerivesKeys(t *testing.T) {
+	type testConfig struct {
+		MaxConns int `env:",default=10"`
+		DB       struct {
+			Host string
+		}
+	}
+
+	vars, err := Describe(&testConfig{}, WithAutoKeys())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var keys []string
+	for _, v := range vars {
+		keys = append(keys, v.Key)
+	}
+	if want := []string{"MAX_CONNS", "DB_HOST"}; !slices.Equal(keys, want) {
+		t.Fatalf("expected keys %v, got %v", want, keys)
+	}
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxCollectionIndex bounds slice indexes discovered in the source so a stray
+// variable such as SERVERS_999999999_HOST cannot force a huge allocation.
+const maxCollectionIndex = 10000
+
+// isStructCollection reports whether t is a slice, or a map with string keys, whose
+// elements are nested structs or pointers to them.
+func isStructCollection(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		return isNestedStruct(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && isNestedStruct(t.Elem())
+	default:
+		return false
+	}
+}
+
+func collectionPlaceholder(t reflect.Type) string {
+	if t.Kind() == reflect.Slice {
+		return "<N>"
+	}
+	return "<KEY>"
+}
+
+// loadCollectionField fills a struct slice or map with one element per index or
+// key found under the collection's prefix, such as PREFIX_0_HOST or PREFIX_EU_HOST.
+func (l *loader) loadCollectionField(field reflect.Value, sc scope) ([]*FieldError, bool) {
+	fieldType := field.Type()
+	keys := elementKeys(indirectType(fieldType.Elem()), sc.auto)
+	if l.keepsCollection(!field.IsNil()) {
+		l.skipCollection(sc, keys)
+		return nil, false
+	}
+	ids, fieldErr := l.collectionIDs(sc, keys)
+	if fieldErr != nil {
+		return []*FieldError{fieldErr}, false
+	}
+	if len(ids) == 0 {
+		return nil, false
+	}
+
+	if fieldType.Kind() == reflect.Slice {
+		return l.loadStructSlice(field, sc, ids)
+	}
+	return l.loadStructMap(field, sc, ids)
+}
+
+// collectionIDs lists the element ids found in the source under the collection at
+// sc, given the keys of an element relative to its prefix.
+func (l *loader) collectionIDs(sc scope, elementKeys []string) ([]string, *FieldError) {
+	lister, ok := l.source.(KeyLister)
+	if !ok {
+		return nil, &FieldError{Field: sc.path, Kind: KindTag, Err: errors.New("source cannot list keys to discover struct collection elements")}
+	}
+	return discoverCollectionIDs(lister.Keys(), sc.prefix, elementKeys), nil
+}
+
+// sliceLength returns the length of a struct slice whose discovered ids are ids:
+// one past the largest decimal index. Other ids are ignored.
+func sliceLength(sc scope, ids []string) (int, *FieldError) {
+	length := 0
+	for _, id := range ids {
+		index, err := strconv.Atoi(id)
+		if err != nil || index < 0 || strconv.Itoa(index) != id {
+			continue
+		}
+		if index > maxCollectionIndex {
+			return 0, &FieldError{Field: sc.path, Kind: KindParse, Err: fmt.Errorf("element index %d exceeds limit %d", index, maxCollectionIndex)}
+		}
+		length = max(length, index+1)
+	}
+	return length, nil
+}
+
+func (l *loader) loadStructSlice(field reflect.Value, sc scope, ids []string) ([]*FieldError, bool) {
+	length, fieldErr := sliceLength(sc, ids)
+	if fieldErr != nil {
+		return []*FieldError{fieldErr}, false
+	}
+	if length == 0 {
+		return nil, false
+	}
+
+	var errs []*FieldError
+	values := reflect.MakeSlice(field.Type(), length, length)
+	for i := 0; i < length; i++ {
+		elementErrs := l.loadCollectionElement(values.Index(i), sc.element(strconv.Itoa(i)))
+		errs = append(errs, elementErrs...)
+	}
+	field.Set(values)
+	return errs, true
+}
+
+func (l *loader) loadStructMap(field reflect.Value, sc scope, ids []string) ([]*FieldError, bool) {
+	fieldType := field.Type()
+
+	var errs []*FieldError
+	values := reflect.MakeMapWithSize(fieldType, len(ids))
+	for _, id := range ids {
+		element := reflect.New(fieldType.Elem()).Elem()
+		elementErrs := l.loadCollectionElement(element, sc.element(id))
+		errs = append(errs, elementErrs...)
+		values.SetMapIndex(reflect.ValueOf(id).Convert(fieldType.Key()), element)
+	}
+	field.Set(values)
+	return errs, true
+}
+
+func (l *loader) loadCollectionElement(element reflect.Value, sc scope) []*FieldError {
+	if element.Kind() == reflect.Pointer {
+		element.Set(reflect.New(element.Type().Elem()))
+		element = element.Elem()
+	}
+	errs, _ := l.loadStruct(element, sc)
+	return errs
+}
+
+// element returns the scope of the collection element identified by id.
+func (s scope) element(id string) scope {
+	child := s
+	child.path = fmt.Sprintf("%s[%s]", s.path, id)
+	child.prefix = s.prefix + id + "_"
+	child.optional = false
+	return child
+}
+
+// elementKeys lists the keys, relative to the element prefix, that identify an
+// element of a struct collection: each field key, its KEY_FILE companion and its
+// aliases.
+func elementKeys(elementType reflect.Type, auto bool) []string {
+	d := &describer{visiting: map[reflect.Type]bool{}}
+	d.describeStruct(elementType, scope{auto: auto})
+
+	var keys []string
+	for _, v := range d.vars {
+		if strings.Contains(v.Key, "<") {
+			continue
+		}
+		keys = append(keys, v.Key, v.Key+fileKeySuffix)
+		keys = append(keys, v.Aliases...)
+	}
+	return keys
+}
+
+// discoverCollectionIDs returns the sorted, distinct ids for which some source key
+// has the form prefix + id + "_" + element key. When a key ends with more than one
+// element key, such as HOST and BACKUP_HOST, the longest one decides its id, so
+// PREFIX_EU_BACKUP_HOST belongs to element EU rather than EU_BACKUP.
+func discoverCollectionIDs(keys []string, prefix string, elementKeys []string) []string {
+	seen := map[string]struct{}{}
+	for _, key := range keys {
+		rest, ok := strings.CutPrefix(key, prefix)
+		if !ok {
+			continue
+		}
+		var id string
+		for _, elementKey := range elementKeys {
+			if candidate, ok := strings.CutSuffix(rest, "_"+elementKey); ok && candidate != "" && (id == "" || len(candidate) < len(id)) {
+				id = candidate
+			}
+		}
+		if id != "" {
+			seen[id] = struct{}{}
+		}
+	}
+
+	ids := make([]string, 0, len(seen))
+	for id := range seen {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
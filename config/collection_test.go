@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type upstreamConfig struct {
+	Host   string `env:"HOST,required"`
+	Port   int    `env:"PORT,default=80,min=1"`
+	Weight int    `env:"WEIGHT,default=1"`
+	Token  string `env:"TOKEN,secret"`
+}
+
+type lookupFunc func(key string) (string, bool)
+
+func (f lookupFunc) Lookup(key string) (string, bool) {
+	return f(key)
+}
+
+func TestLoadReadsIndexedStructSlices(t *testing.T) {
+	type testConfig struct {
+		Upstreams []upstreamConfig  `envPrefix:"UPSTREAMS_"`
+		Backups   []*upstreamConfig `envPrefix:"BACKUPS_"`
+		Unused    []upstreamConfig  `envPrefix:"UNUSED_"`
+		Untagged  []upstreamConfig
+	}
+
+	source := MapSource{
+		"APP_UPSTREAMS_0_HOST":     "a.internal",
+		"APP_UPSTREAMS_0_PORT":     "8080",
+		"APP_UPSTREAMS_1_HOST":     "b.internal",
+		"APP_UPSTREAMS_1_WEIGHT":   "3",
+		"APP_UPSTREAMS_X_HOST":     "ignored",
+		"APP_UPSTREAMS_01_HOST":    "ignored",
+		"APP_BACKUPS_0_TOKEN_FILE": writeValueFile(t, "token", "s3cret\n"),
+		"APP_BACKUPS_0_HOST":       "backup.internal",
+		"APP_UNTAGGED_0_HOST":      "ignored",
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source, WithPrefix("APP_")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []upstreamConfig{
+		{Host: "a.internal", Port: 8080, Weight: 1},
+		{Host: "b.internal", Port: 80, Weight: 3},
+	}
+	if !reflect.DeepEqual(cfg.Upstreams, want) {
+		t.Fatalf("expected upstreams %+v, got %+v", want, cfg.Upstreams)
+	}
+	if len(cfg.Backups) != 1 || cfg.Backups[0].Host != "backup.internal" || cfg.Backups[0].Token != "s3cret" {
+		t.Fatalf("expected one backup read through KEY_FILE, got %+v", cfg.Backups)
+	}
+	if cfg.Unused != nil || cfg.Untagged != nil {
+		t.Fatalf("expected collections without variables to stay nil, got %+v and %+v", cfg.Unused, cfg.Untagged)
+	}
+}
+
+func TestLoadReadsKeyedStructMaps(t *testing.T) {
+	type testConfig struct {
+		Upstreams map[string]upstreamConfig `envPrefix:"UPSTREAMS_"`
+	}
+
+	source := MapSource{
+		"UPSTREAMS_PRIMARY_HOST":   "primary.internal",
+		"UPSTREAMS_EU_WEST_HOST":   "eu.internal",
+		"UPSTREAMS_EU_WEST_WEIGHT": "5",
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]upstreamConfig{
+		"PRIMARY": {Host: "primary.internal", Port: 80, Weight: 1},
+		"EU_WEST": {Host: "eu.internal", Port: 80, Weight: 5},
+	}
+	if !reflect.DeepEqual(cfg.Upstreams, want) {
+		t.Fatalf("expected upstreams %+v, got %+v", want, cfg.Upstreams)
+	}
+}
+
+func TestLoadStructMapPrefersLongestElementKey(t *testing.T) {
+	type up struct {
+		Host       string `env:"HOST"`
+		BackupHost string `env:"BACKUP_HOST"`
+	}
+	type testConfig struct {
+		Ups map[string]up `envPrefix:"UPS_"`
+	}
+
+	source := MapSource{
+		"UPS_EU_HOST":        "h",
+		"UPS_EU_BACKUP_HOST": "b",
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := map[string]up{"EU": {Host: "h", BackupHost: "b"}}
+	if !reflect.DeepEqual(cfg.Ups, want) {
+		t.Fatalf("expected %+v, got %+v", want, cfg.Ups)
+	}
+}
+
+func TestLoadReportsStructCollectionElementErrors(t *testing.T) {
+	type testConfig struct {
+		Upstreams []upstreamConfig `envPrefix:"UPSTREAMS_"`
+	}
+
+	source := MapSource{
+		"UPSTREAMS_0_HOST":  "a.internal",
+		"UPSTREAMS_2_PORT":  "0",
+		"UPSTREAMS_2_TOKEN": "",
+	}
+
+	err := LoadFrom(&testConfig{}, source)
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected *LoadError, got %T (%v)", err, err)
+	}
+
+	var fields []string
+	for _, fieldErr := range loadErr.Errors {
+		fields = append(fields, fieldErr.Field)
+	}
+	want := []string{"Upstreams[1].Host", "Upstreams[2].Host", "Upstreams[2].Port"}
+	if !slices.Equal(fields, want) {
+		t.Fatalf("expected errors for %v, got %v (%v)", want, fields, err)
+	}
+	if got := err.Error(); !strings.Contains(got, `environment variable "UPSTREAMS_1_HOST" is required`) {
+		t.Fatalf("expected element key in error, got %q", got)
+	}
+}
+
+func TestLoadRejectsStructCollectionsWithoutKeyLister(t *testing.T) {
+	type testConfig struct {
+		Upstreams []upstreamConfig `envPrefix:"UPSTREAMS_"`
+	}
+
+	source := lookupFunc(func(string) (string, bool) { return "", false })
+	err := LoadFrom(&testConfig{}, source)
+	if !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("expected tag error, got %v", err)
+	}
+}
+
+func TestDescribeUsesStructCollectionPlaceholders(t *testing.T) {
+	type testConfig struct {
+		Upstreams []upstreamConfig          `envPrefix:"UPSTREAMS_"`
+		Regions   map[string]upstreamConfig `envPrefix:"REGIONS_"`
+	}
+
+	vars, err := Describe(&testConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var keys []string
+	for _, v := range vars {
+		keys = append(keys, v.Field+"="+v.Key)
+	}
+	for _, want := range []string{"Upstreams[].Host=UPSTREAMS_<N>_HOST", "Regions[].Weight=REGIONS_<KEY>_WEIGHT"} {
+		if !slices.Contains(keys, want) {
+			t.Fatalf("expected %s in %v", want, keys)
+		}
+	}
+}
+
+func TestMultiSourceListsKeysOfListers(t *testing.T) {
+	source := MultiSource{
+		MapSource{"A": "1", "B": "2"},
+		lookupFunc(func(string) (string, bool) { return "", false }),
+		MapSource{"B": "3", "C": "4"},
+	}
+
+	keys := source.Keys()
+	slices.Sort(keys)
+	if !slices.Equal(keys, []string{"A", "B", "C"}) {
+		t.Fatalf("expected union of keys, got %v", keys)
+	}
+}
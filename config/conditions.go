@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	groupExactlyOne = "exactlyone"
+	groupAtMostOne  = "atmostone"
+	groupAtLeastOne = "atleastone"
+)
+
+func isGroupMode(mode string) bool {
+	switch mode {
+	case groupExactlyOne, groupAtMostOne, groupAtLeastOne:
+		return true
+	default:
+		return false
+	}
+}
+
+// conditions collects the fields of one struct that carry required_if,
+// required_with or group options, and the values of every field that was set, so
+// the rules can be checked once the whole struct has loaded.
+type conditions struct {
+	values map[string]string
+	fields []conditionalField
+}
+
+type conditionalField struct {
+	path string
+	opts fieldOptions
+	set  bool
+}
+
+func (c *conditions) add(path string, opts fieldOptions, value resolvedValue) {
+	if value.set() {
+		if c.values == nil {
+			c.values = map[string]string{}
+		}
+		c.values[opts.key] = value.raw
+	}
+	if opts.requiredIfKey != "" || len(opts.requiredWith) > 0 || opts.group != "" {
+		c.fields = append(c.fields, conditionalField{path: path, opts: opts, set: value.set()})
+	}
+}
+
+// lookupCondition returns the value of key, preferring a field of the struct, which may
+// have come from a default, alias or file, over the source.
+func (l *loader) lookupCondition(c conditions, key string) (string, bool) {
+	if value, ok := c.values[key]; ok {
+		return value, true
+	}
+	return l.lookup(key)
+}
+
+// checkConditions evaluates the required_if, required_with and group options of a
+// struct after all of its fields have loaded.
+func (l *loader) checkConditions(sc scope, c conditions) []*FieldError {
+	var (
+		errs   []*FieldError
+		groups []string
+		byName = map[string][]conditionalField{}
+	)
+
+	for _, field := range c.fields {
+		if field.opts.group != "" {
+			if _, ok := byName[field.opts.group]; !ok {
+				groups = append(groups, field.opts.group)
+			}
+			byName[field.opts.group] = append(byName[field.opts.group], field)
+		}
+		if field.set {
+			continue
+		}
+
+		if key := field.opts.requiredIfKey; key != "" {
+			if value, ok := l.lookupCondition(c, key); ok && slices.Contains(field.opts.requiredIfValues, value) {
+				errs = append(errs, &FieldError{
+					Field: field.path,
+					Key:   field.opts.key,
+					Kind:  KindMissing,
+					Err:   requiredErrorf("required when %q is %q", key, value),
+				})
+				continue
+			}
+		}
+		for _, key := range field.opts.requiredWith {
+			if _, ok := l.lookupCondition(c, key); ok {
+				errs = append(errs, &FieldError{
+					Field: field.path,
+					Key:   field.opts.key,
+					Kind:  KindMissing,
+					Err:   requiredErrorf("required when %q is set", key),
+				})
+				break
+			}
+		}
+	}
+
+	for _, name := range groups {
+		if fieldErr := checkGroup(sc, name, byName[name]); fieldErr != nil {
+			errs = append(errs, fieldErr)
+		}
+	}
+	return errs
+}
+
+// checkGroup reports a group whose number of set fields violates its mode. Group
+// violations are attributed to the struct rather than to any one field.
+func checkGroup(sc scope, name string, fields []conditionalField) *FieldError {
+	mode := fields[0].opts.groupMode
+	keys := make([]string, 0, len(fields))
+	count := 0
+	for _, field := range fields {
+		if field.opts.groupMode != mode {
+			return &FieldError{Field: field.path, Kind: KindTag, Err: fmt.Errorf("group %q is declared both %s and %s", name, mode, field.opts.groupMode)}
+		}
+		keys = append(keys, fmt.Sprintf("%q", field.opts.key))
+		if field.set {
+			count++
+		}
+	}
+
+	var rule string
+	switch {
+	case mode == groupExactlyOne && count != 1:
+		rule = "exactly one"
+	case mode == groupAtMostOne && count > 1:
+		rule = "at most one"
+	case mode == groupAtLeastOne && count == 0:
+		rule = "at least one"
+	default:
+		return nil
+	}
+
+	return &FieldError{
+		Field: sc.path,
+		Kind:  KindValidation,
+		Err:   validationErrorf("group %s: %s of %s must be set, got %d", name, rule, strings.Join(keys, ", "), count),
+	}
+}
@@ -0,0 +1,145 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type storageConfig struct {
+	Storage  string `env:"STORAGE,default=local,oneof=local|s3|gcs"`
+	S3Bucket string `env:"S3_BUCKET,required_if=STORAGE:s3"`
+	Bucket   string `env:"BUCKET,required_if=STORAGE:s3|gcs"`
+	TLSCert  string `env:"TLS_CERT,required_with=TLS_KEY"`
+	TLSKey   string `env:"TLS_KEY,required_with=TLS_CERT"`
+}
+
+func TestLoadChecksConditionalRequirements(t *testing.T) {
+	if err := LoadFrom(&storageConfig{}, MapSource{}); err != nil {
+		t.Fatalf("expected unmet conditions to be ignored, got %v", err)
+	}
+
+	ok := MapSource{"APP_STORAGE": "s3", "APP_S3_BUCKET": "assets", "APP_BUCKET": "assets", "APP_TLS_CERT": "cert", "APP_TLS_KEY": "key"}
+	if err := LoadFrom(&storageConfig{}, ok, WithPrefix("APP_")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := LoadFrom(&storageConfig{}, MapSource{"APP_STORAGE": "s3", "APP_TLS_KEY": "key"}, WithPrefix("APP_"))
+	if !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected required error, got %v", err)
+	}
+
+	got := err.Error()
+	for _, want := range []string{
+		`field S3Bucket: environment variable "APP_S3_BUCKET" is required when "APP_STORAGE" is "s3"`,
+		`field Bucket: environment variable "APP_BUCKET" is required when "APP_STORAGE" is "s3"`,
+		`field TLSCert: environment variable "APP_TLS_CERT" is required when "APP_TLS_KEY" is set`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+}
+
+func TestLoadConditionsSeeDefaults(t *testing.T) {
+	type testConfig struct {
+		Storage  string `env:"STORAGE,default=s3"`
+		S3Bucket string `env:"S3_BUCKET,required_if=STORAGE:s3"`
+	}
+
+	err := LoadFrom(&testConfig{}, MapSource{})
+	if !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected default STORAGE to require S3_BUCKET, got %v", err)
+	}
+}
+
+func TestLoadChecksExclusiveGroups(t *testing.T) {
+	type authConfig struct {
+		Token     string `env:"TOKEN,group=auth,exactlyone,secret"`
+		TokenFile string `env:"TOKEN_FILE,group=auth,exactlyone"`
+		Proxy     string `env:"PROXY,group=network,atmostone"`
+		Socket    string `env:"SOCKET,group=network,atmostone"`
+	}
+	type testConfig struct {
+		Auth authConfig `envPrefix:"AUTH_"`
+	}
+
+	for _, source := range []MapSource{{"AUTH_TOKEN": "t"}, {"AUTH_TOKEN_FILE": "/run/token"}} {
+		var cfg testConfig
+		if err := LoadFrom(&cfg, source); err != nil {
+			t.Fatalf("unexpected error for %v: %v", source, err)
+		}
+		if cfg.Auth.Token != source["AUTH_TOKEN"] || cfg.Auth.TokenFile != source["AUTH_TOKEN_FILE"] {
+			t.Fatalf("unexpected config %+v for %v", cfg, source)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		source MapSource
+		want   string
+	}{
+		{
+			name:   "none",
+			source: MapSource{},
+			want:   `field Auth: group auth: exactly one of "AUTH_TOKEN", "AUTH_TOKEN_FILE" must be set, got 0`,
+		},
+		{
+			name:   "both tokens",
+			source: MapSource{"AUTH_TOKEN": "t", "AUTH_TOKEN_FILE": "/run/token"},
+			want:   `field Auth: group auth: exactly one of "AUTH_TOKEN", "AUTH_TOKEN_FILE" must be set, got 2`,
+		},
+		{
+			name:   "both",
+			source: MapSource{"AUTH_TOKEN": "t", "AUTH_TOKEN_FILE": "/run/token", "AUTH_PROXY": "p", "AUTH_SOCKET": "s"},
+			want:   `field Auth: group network: at most one of "AUTH_PROXY", "AUTH_SOCKET" must be set, got 2`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := LoadFrom(&testConfig{}, tt.source)
+			if !errors.Is(err, ErrValidation) {
+				t.Fatalf("expected validation error, got %v", err)
+			}
+			if got := err.Error(); !strings.Contains(got, tt.want) {
+				t.Fatalf("expected %q in %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadRejectsInvalidConditionOptions(t *testing.T) {
+	type testConfig struct {
+		MissingValue string `env:"A,required_if=STORAGE"`
+		EmptyKey     string `env:"B,required_with=C|"`
+		NoMode       string `env:"D,group=auth"`
+		Mixed1       string `env:"E,group=mixed,exactlyone"`
+		Mixed2       string `env:"F,group=mixed,atleastone"`
+	}
+
+	err := LoadFrom(&testConfig{}, MapSource{"E": "x"})
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected *LoadError, got %T (%v)", err, err)
+	}
+
+	var fields []string
+	for _, fieldErr := range loadErr.Errors {
+		if fieldErr.Kind != KindTag {
+			t.Fatalf("expected tag error, got %v", fieldErr)
+		}
+		fields = append(fields, fieldErr.Field)
+	}
+	if want := []string{"MissingValue", "EmptyKey", "NoMode", "Mixed2"}; !slices.Equal(fields, want) {
+		t.Fatalf("expected tag errors for %v, got %v (%v)", want, fields, err)
+	}
+}
+
+func TestGetRejectsConditionOptions(t *testing.T) {
+	_, err := Get[string]("S3_BUCKET,required_if=STORAGE:s3", WithSource(MapSource{}))
+	if !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("expected tag error, got %v", err)
+	}
+}
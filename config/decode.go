@@ -0,0 +1,268 @@
+package config
+
+import (
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// setFunc parses raw into field and validates the result against the tag
+// constraints in opts. field is left untouched when either step fails. The rules
+// were chosen when the function was compiled, from the field's type and from tag
+// options that do not depend on where the field sits, such as format and layout;
+// opts supplies the rest at run time.
+type setFunc func(l *loader, field reflect.Value, raw string, opts fieldOptions) error
+
+// decodeFunc parses raw into field, which holds the zero value of its type.
+type decodeFunc func(l *loader, field reflect.Value, raw string, opts fieldOptions) error
+
+// compileSetter returns the setFunc for values of type t with the given tag options.
+// Plans compile one per value field, so Load does not repeat the type switch below
+// for every value it sets.
+func compileSetter(t reflect.Type, opts fieldOptions) setFunc {
+	decode := compileDecoder(t, opts)
+	return func(l *loader, field reflect.Value, raw string, opts fieldOptions) error {
+		value := reflect.New(t).Elem()
+		if err := decode(l, value, raw, opts); err != nil {
+			return err
+		}
+		if err := validateValue(value, opts); err != nil {
+			return err
+		}
+		field.Set(value)
+		return nil
+	}
+}
+
+// compileDecoder returns the decodeFunc for values of type t. Parsers installed
+// with WithParser or RegisterParser are looked up on every call, since they may
+// change between calls, and take precedence over the compiled rules.
+func compileDecoder(t reflect.Type, opts fieldOptions) decodeFunc {
+	builtin := compileBuiltin(t, opts)
+	return func(l *loader, field reflect.Value, raw string, opts fieldOptions) error {
+		if parse, ok := l.parserFor(t); ok {
+			value, err := parse(raw, opts.parseOptions())
+			if err != nil {
+				return err
+			}
+			field.Set(value)
+			return nil
+		}
+		return builtin(l, field, raw, opts)
+	}
+}
+
+func compileBuiltin(t reflect.Type, opts fieldOptions) decodeFunc {
+	if decode, ok := compileFormatted(t, opts.format); ok {
+		return decode
+	}
+
+	switch {
+	case t == timeDurationType:
+		return func(_ *loader, field reflect.Value, raw string, _ fieldOptions) error {
+			value, err := time.ParseDuration(raw)
+			if err != nil {
+				return err
+			}
+			field.SetInt(int64(value))
+			return nil
+		}
+	case t == timeTimeType:
+		return func(_ *loader, field reflect.Value, raw string, opts fieldOptions) error {
+			if opts.layout == "" {
+				return tagErrorf("time.Time fields require layout")
+			}
+			value, err := time.Parse(opts.layout, raw)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.ValueOf(value))
+			return nil
+		}
+	case t == urlType:
+		return func(_ *loader, field reflect.Value, raw string, _ fieldOptions) error {
+			value, err := parseURL(raw)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.ValueOf(*value))
+			return nil
+		}
+	case t.Kind() == reflect.Pointer:
+		elem := compileDecoder(t.Elem(), opts)
+		return func(l *loader, field reflect.Value, raw string, opts fieldOptions) error {
+			ptr := reflect.New(t.Elem())
+			if err := elem(l, ptr.Elem(), raw, opts); err != nil {
+				return err
+			}
+			field.Set(ptr)
+			return nil
+		}
+	case isOptional(t):
+		elem := compileDecoder(t.Field(0).Type, opts)
+		return func(l *loader, field reflect.Value, raw string, opts fieldOptions) error {
+			optional := field.Addr().Interface().(optionalValue)
+			if err := elem(l, optional.optionalTarget(), raw, opts); err != nil {
+				return err
+			}
+			optional.markSet()
+			return nil
+		}
+	case isUnmarshaler(reflect.PointerTo(t)):
+		return func(_ *loader, field reflect.Value, raw string, _ fieldOptions) error {
+			ptr := reflect.New(t)
+			if err := unmarshalValue(ptr.Interface(), raw); err != nil {
+				return err
+			}
+			field.Set(ptr.Elem())
+			return nil
+		}
+	case t.Kind() == reflect.Struct:
+		return failDecoder(tagErrorf("nested structs are not supported"))
+	case t.Kind() == reflect.String:
+		return func(_ *loader, field reflect.Value, raw string, _ fieldOptions) error {
+			field.SetString(raw)
+			return nil
+		}
+	case t.Kind() == reflect.Bool:
+		return func(_ *loader, field reflect.Value, raw string, _ fieldOptions) error {
+			value, err := strconv.ParseBool(raw)
+			if err != nil {
+				return err
+			}
+			field.SetBool(value)
+			return nil
+		}
+	case isSignedInteger(t.Kind()):
+		bits := t.Bits()
+		return func(_ *loader, field reflect.Value, raw string, opts fieldOptions) error {
+			value, err := parseSignedInteger(raw, bits, opts.format)
+			if err != nil {
+				return err
+			}
+			field.SetInt(value)
+			return nil
+		}
+	case isUnsignedInteger(t.Kind()) && t.Kind() != reflect.Uintptr:
+		bits := t.Bits()
+		return func(_ *loader, field reflect.Value, raw string, _ fieldOptions) error {
+			value, err := parseUnsignedInteger(raw, bits)
+			if err != nil {
+				return err
+			}
+			field.SetUint(value)
+			return nil
+		}
+	case t.Kind() == reflect.Float32, t.Kind() == reflect.Float64:
+		bits := t.Bits()
+		return func(_ *loader, field reflect.Value, raw string, _ fieldOptions) error {
+			value, err := strconv.ParseFloat(raw, bits)
+			if err != nil {
+				return err
+			}
+			field.SetFloat(value)
+			return nil
+		}
+	case t.Kind() == reflect.Slice:
+		set := compileSetter(t.Elem(), opts.elementOptions())
+		return func(l *loader, field reflect.Value, raw string, opts fieldOptions) error {
+			parts, err := parseStringSlice(raw, opts.sep)
+			if err != nil {
+				return err
+			}
+			elementOpts := opts.elementOptions()
+			values := reflect.MakeSlice(t, len(parts), len(parts))
+			for i, part := range parts {
+				if err := set(l, values.Index(i), part, elementOpts); err != nil {
+					return wrapElementError(err, "element %d", i)
+				}
+			}
+			field.Set(values)
+			return nil
+		}
+	case t.Kind() == reflect.Map:
+		setKey := compileSetter(t.Key(), opts.keyOptions())
+		setValue := compileSetter(t.Elem(), opts.elementOptions())
+		return func(l *loader, field reflect.Value, raw string, opts fieldOptions) error {
+			entries, err := parseMap(raw, opts.kvSep, opts.entrySep)
+			if err != nil {
+				return err
+			}
+			keyOpts, elementOpts := opts.keyOptions(), opts.elementOptions()
+			values := reflect.MakeMapWithSize(t, len(entries))
+			for _, entryKey := range slices.Sorted(maps.Keys(entries)) {
+				key := reflect.New(t.Key()).Elem()
+				if err := setKey(l, key, entryKey, keyOpts); err != nil {
+					return wrapElementError(err, "key %q", entryKey)
+				}
+				value := reflect.New(t.Elem()).Elem()
+				if err := setValue(l, value, entries[entryKey], elementOpts); err != nil {
+					return wrapElementError(err, "key %q", entryKey)
+				}
+				values.SetMapIndex(key, value)
+			}
+			field.Set(values)
+			return nil
+		}
+	default:
+		return failDecoder(tagErrorf("unsupported field type %s", t))
+	}
+}
+
+// compileFormatted returns the decoder for an encoding format, or false when t
+// should be decoded by the regular rules instead: pointers and Optional values are
+// unwrapped first, and slices and maps apply base64 and hex to each element.
+func compileFormatted(t reflect.Type, format string) (decodeFunc, bool) {
+	switch format {
+	case formatJSON:
+		if isOptional(t) {
+			return nil, false
+		}
+		return func(_ *loader, field reflect.Value, raw string, _ fieldOptions) error {
+			ptr := reflect.New(t)
+			if err := json.Unmarshal([]byte(raw), ptr.Interface()); err != nil {
+				return err
+			}
+			field.Set(ptr.Elem())
+			return nil
+		}, true
+	case formatBase64, formatHex:
+		switch {
+		case t.Kind() == reflect.String:
+			return func(_ *loader, field reflect.Value, raw string, _ fieldOptions) error {
+				decoded, err := decodeBinary(raw, format)
+				if err != nil {
+					return err
+				}
+				field.SetString(string(decoded))
+				return nil
+			}, true
+		case isByteSlice(t):
+			return func(_ *loader, field reflect.Value, raw string, _ fieldOptions) error {
+				decoded, err := decodeBinary(raw, format)
+				if err != nil {
+					return err
+				}
+				field.SetBytes(decoded)
+				return nil
+			}, true
+		case t.Kind() == reflect.Pointer, isOptional(t), t.Kind() == reflect.Slice, t.Kind() == reflect.Map:
+			return nil, false
+		default:
+			return failDecoder(tagErrorf("format=%s requires a string or []byte field, got %s", format, t)), true
+		}
+	default:
+		return nil, false
+	}
+}
+
+// failDecoder returns a decoder that always fails with err. Unsupported types are
+// only reported when a value is present, as Load always has.
+func failDecoder(err error) decodeFunc {
+	return func(*loader, reflect.Value, string, fieldOptions) error {
+		return err
+	}
+}
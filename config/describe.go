@@ -0,0 +1,212 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Variable describes one environment variable read by a config struct.
+type Variable struct {
+	// Key is the environment variable name, including any prefixes.
+	Key string
+	// Field is the dotted Go path of the field, such as "DB.Port".
+	Field string
+	// Type is the Go type of the field.
+	Type string
+	// Default is the value used when the variable is unset, if HasDefault is set.
+	Default    string
+	HasDefault bool
+	Required   bool
+	// Aliases lists deprecated keys that are still read.
+	Aliases []string
+	// OneOf lists the allowed values, if constrained.
+	OneOf []string
+	// Description comes from the field's `desc` tag.
+	Description string
+	Secret      bool
+}
+
+// Describe lists the variables a struct reads, in field order, using the same tag
+// rules as Load. Keys of struct collection elements contain a <N> or <KEY>
+// placeholder for the element index or map key. target may be a struct or a
+// pointer to one; only its type is used. Options such as WithPrefix are honored.
+func Describe(target any, opts ...Option) ([]Variable, error) {
+	if target == nil {
+		return nil, errors.New("config target must be a struct or pointer to struct")
+	}
+
+	targetType := reflect.TypeOf(target)
+	if targetType.Kind() == reflect.Pointer {
+		targetType = targetType.Elem()
+	}
+	if targetType.Kind() != reflect.Struct {
+		return nil, errors.New("config target must be a struct or pointer to struct")
+	}
+
+	l := newLoader(opts)
+	d := &describer{visiting: map[reflect.Type]bool{}}
+	d.describeStruct(targetType, scope{prefix: l.prefix, secret: l.redact, auto: l.auto})
+	if len(d.errs) > 0 {
+		return d.vars, &LoadError{Errors: d.errs}
+	}
+	return d.vars, nil
+}
+
+type describer struct {
+	vars     []Variable
+	errs     []*FieldError
+	visiting map[reflect.Type]bool
+}
+
+func (d *describer) describeStruct(targetType reflect.Type, sc scope) {
+	if d.visiting[targetType] {
+		return
+	}
+	d.visiting[targetType] = true
+	defer delete(d.visiting, targetType)
+
+	plan := planFor(targetType, sc.auto)
+	for i, fieldPlan := range plan.fields {
+		structField := targetType.Field(i)
+		spec, err := fieldPlan.resolve(sc)
+		if err != nil {
+			d.errs = append(d.errs, &FieldError{Field: spec.scope.path, Kind: KindTag, Err: err})
+			continue
+		}
+
+		switch spec.kind {
+		case fieldNested:
+			d.describeStruct(indirectType(structField.Type), spec.scope)
+		case fieldCollection:
+			elementScope := spec.scope
+			elementScope.path += "[]"
+			elementScope.prefix += collectionPlaceholder(structField.Type) + "_"
+			d.describeStruct(indirectType(structField.Type.Elem()), elementScope)
+		case fieldValue:
+			d.vars = append(d.vars, Variable{
+				Key:         spec.opts.key,
+				Field:       spec.scope.path,
+				Type:        structField.Type.String(),
+				Default:     spec.opts.defaultVal,
+				HasDefault:  spec.opts.hasDefault,
+				Required:    spec.opts.required,
+				Aliases:     slices.Clone(spec.opts.aliases),
+				OneOf:       slices.Clone(spec.opts.oneOf),
+				Description: structField.Tag.Get("desc"),
+				Secret:      spec.opts.secret,
+			})
+		}
+	}
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Pointer {
+		return t.Elem()
+	}
+	return t
+}
+
+// WriteEnvExample renders vars as a .env.example file. Secret defaults are left blank.
+func WriteEnvExample(w io.Writer, vars []Variable) error {
+	var b strings.Builder
+	for i, v := range vars {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if v.Description != "" {
+			fmt.Fprintf(&b, "# %s\n", v.Description)
+		}
+		if notes := v.notes(); len(notes) > 0 {
+			fmt.Fprintf(&b, "# %s\n", strings.Join(notes, ", "))
+		}
+		value := v.Default
+		if v.Secret {
+			value = ""
+		}
+		fmt.Fprintf(&b, "%s=%s\n", v.Key, value)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteMarkdown renders vars as a Markdown table.
+func WriteMarkdown(w io.Writer, vars []Variable) error {
+	var b strings.Builder
+	b.WriteString("| Variable | Type | Default | Required | Description |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, v := range vars {
+		required := "no"
+		if v.Required {
+			required = "yes"
+		}
+		description := v.Description
+		if len(v.OneOf) > 0 {
+			description = strings.TrimSpace(description + " (one of: " + quoteAll(v.OneOf, "`") + ")")
+		}
+		fmt.Fprintf(&b, "| `%s` | `%s` | %s | %s | %s |\n",
+			v.Key, v.Type, markdownDefault(v), required, escapeMarkdownCell(description))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteUsage renders vars as aligned plain text suitable for -help output.
+func WriteUsage(w io.Writer, vars []Variable) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "Environment variables:")
+	for _, v := range vars {
+		details := v.Description
+		if notes := v.notes(); len(notes) > 0 {
+			details = strings.TrimSpace(details + " (" + strings.Join(notes, ", ") + ")")
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\n", v.Key, v.Type, details)
+	}
+	return tw.Flush()
+}
+
+// notes summarizes the requirement, default and allowed values of v.
+func (v Variable) notes() []string {
+	var notes []string
+	if v.Required && !v.HasDefault {
+		notes = append(notes, "required")
+	}
+	if v.HasDefault {
+		if v.Secret {
+			notes = append(notes, "default: "+redactedValue)
+		} else {
+			notes = append(notes, fmt.Sprintf("default: %q", v.Default))
+		}
+	}
+	if len(v.OneOf) > 0 {
+		notes = append(notes, "one of: "+strings.Join(v.OneOf, "|"))
+	}
+	return notes
+}
+
+func markdownDefault(v Variable) string {
+	switch {
+	case !v.HasDefault:
+		return ""
+	case v.Secret:
+		return redactedValue
+	default:
+		return "`" + escapeMarkdownCell(v.Default) + "`"
+	}
+}
+
+func quoteAll(values []string, quote string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, quote+value+quote)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func escapeMarkdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type describeDBConfig struct {
+	Host     string `env:"HOST,required" desc:"Database host"`
+	Password string `env:"PASSWORD,default=changeme,secret" desc:"Database password"`
+}
+
+type describeConfig struct {
+	Port    int               `env:"PORT,default=8080" desc:"HTTP listen port"`
+	Mode    string            `env:"MODE,default=dev,oneof=dev|prod" desc:"Runtime mode"`
+	Timeout time.Duration     `env:"TIMEOUT"`
+	DB      *describeDBConfig `envPrefix:"DB_"`
+	Ignored string            `env:"-"`
+}
+
+func TestDescribeListsVariables(t *testing.T) {
+	vars, err := Describe(&describeConfig{}, WithPrefix("APP_"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Variable{
+		{Key: "APP_PORT", Field: "Port", Type: "int", Default: "8080", HasDefault: true, Description: "HTTP listen port"},
+		{Key: "APP_MODE", Field: "Mode", Type: "string", Default: "dev", HasDefault: true, OneOf: []string{"dev", "prod"}, Description: "Runtime mode"},
+		{Key: "APP_TIMEOUT", Field: "Timeout", Type: "time.Duration"},
+		{Key: "APP_DB_HOST", Field: "DB.Host", Type: "string", Required: true, Description: "Database host"},
+		{Key: "APP_DB_PASSWORD", Field: "DB.Password", Type: "string", Default: "changeme", HasDefault: true, Description: "Database password", Secret: true},
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Fatalf("expected variables:\n%+v\ngot:\n%+v", want, vars)
+	}
+}
+
+func TestDescribeReportsInvalidTags(t *testing.T) {
+	type testConfig struct {
+		Port int `env:"PORT,bogus"`
+	}
+
+	if _, err := Describe(testConfig{}); err == nil || !strings.Contains(err.Error(), `unsupported env option "bogus"`) {
+		t.Fatalf("expected tag error, got %v", err)
+	}
+	if _, err := Describe(42); err == nil {
+		t.Fatal("expected error for non-struct target")
+	}
+}
+
+func TestVariableRenderers(t *testing.T) {
+	vars, err := Describe(describeConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var envExample strings.Builder
+	if err := WriteEnvExample(&envExample, vars); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantExample := `# HTTP listen port
+# default: "8080"
+PORT=8080
+
+# Runtime mode
+# default: "dev", one of: dev|prod
+MODE=dev
+
+TIMEOUT=
+
+# Database host
+# required
+DB_HOST=
+
+# Database password
+# default: [REDACTED]
+DB_PASSWORD=
+`
+	if got := envExample.String(); got != wantExample {
+		t.Fatalf("unexpected .env.example:\n%s", got)
+	}
+
+	var markdown strings.Builder
+	if err := WriteMarkdown(&markdown, vars); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"| Variable | Type | Default | Required | Description |",
+		"| `PORT` | `int` | `8080` | no | HTTP listen port |",
+		"| `MODE` | `string` | `dev` | no | Runtime mode (one of: `dev`, `prod`) |",
+		"| `DB_HOST` | `string` |  | yes | Database host |",
+		"| `DB_PASSWORD` | `string` | [REDACTED] | no | Database password |",
+	} {
+		if !strings.Contains(markdown.String(), want) {
+			t.Fatalf("expected markdown to contain %q, got:\n%s", want, markdown.String())
+		}
+	}
+
+	var usage strings.Builder
+	if err := WriteUsage(&usage, vars); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{
+		"Environment variables:",
+		"  PORT         int            HTTP listen port (default: \"8080\")",
+		"  DB_HOST      string         Database host (required)",
+	} {
+		if !strings.Contains(usage.String(), want) {
+			t.Fatalf("expected usage to contain %q, got:\n%s", want, usage.String())
+		}
+	}
+}
@@ -1,13 +1,14 @@
 package config
 
 import (
+	"encoding"
 	"errors"
+	"flag"
 	"fmt"
 	"math"
 	"net/url"
-	"os"
 	"reflect"
-	"slices"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -17,20 +18,62 @@ var (
 	timeDurationType = reflect.TypeOf(time.Duration(0))
 	timeTimeType     = reflect.TypeOf(time.Time{})
 	urlType          = reflect.TypeOf(url.URL{})
+
+	textUnmarshalerType   = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+	flagValueType         = reflect.TypeOf((*flag.Value)(nil)).Elem()
 )
 
 // Load populates a struct pointer from environment variables described by env tags.
+// Use WithSource or LoadFrom to read from a Lookuper other than the process environment.
 //
 // Supported tag options:
 //   - `env:"KEY"` reads KEY into the field
 //   - `required` fails when the key is unset
 //   - `default=value` uses value when the key is unset
-//   - `sep=|` overrides []string separators (default `,`)
+//   - `sep=|` overrides slice separators (default `,`)
 //   - `entrysep=;` and `kvsep=:` override map separators (defaults `,` and `=`)
 //   - `layout=2006-01-02` defines the time.Time layout
 //   - `oneof=a|b|c` constrains string values
+//   - `min=`, `max=` bound numbers, durations and byte sizes, or the length of
+//     strings, slices and maps
+//   - `len=`, `minlen=`, `maxlen=` bound the length of strings, slices and maps
+//   - `pattern=regexp` constrains string values to a regular expression
 //   - `format=bytes` enables byte-size parsing for integer fields
-func Load(target any) error {
+//   - `format=json` decodes the value as JSON into any field type
+//   - `format=base64` and `format=hex` decode string and []byte fields
+//   - `secret` (or `sensitive`) keeps the raw value out of every error message
+//   - `file` treats the value as a path and reads the field from that file
+//   - `alias=OLD|OLDER` falls back to deprecated keys in order, reporting a Warning
+//   - `expand` substitutes ${VAR} and ${VAR:-fallback} in the value or default
+//   - `auto` derives the key from the field name, as in `env:",auto,default=10"`
+//   - `required_if=KEY:a|b` requires the field when KEY is set to a or b
+//   - `required_with=KEY|OTHER` requires the field when any of the keys is set
+//   - `group=NAME,exactlyone` (or atmostone, atleastone) limits how many fields of
+//     the group may be set
+//
+// When KEY is unset but KEY_FILE is set, the field is read from the file it names.
+//
+// Pointers to any supported type are allocated only when the key is set or has a
+// default, so nil means "not configured". Optional[T] records the same distinction
+// without a pointer.
+//
+// Fields whose type implements encoding.TextUnmarshaler, encoding.BinaryUnmarshaler
+// or flag.Value (on a value or pointer receiver) are decoded through that interface.
+// Nested struct fields tagged `envPrefix:"PREFIX_"` prepend PREFIX_ to every key
+// beneath them; prefixes compose through multiple levels of nesting. Slices and
+// string-keyed maps of structs tagged with envPrefix load one element per index or
+// key discovered in the source, such as PREFIX_0_HOST or PREFIX_PRIMARY_HOST.
+//
+// After its fields are populated, the root struct and every nested struct implementing
+// Validator is validated, and the errors are aggregated with the field errors.
+// WithStrict additionally reports variables under a prefix that no field read.
+//
+// WithAutoKeys, or `env:",auto"` on a nested struct field, derives missing keys and
+// prefixes from field names: DB.MaxConns reads DB_MAX_CONNS.
+// Parsers registered with RegisterParser or passed with WithParser take precedence
+// over every built-in rule.
+func Load(target any, opts ...Option) error {
 	if target == nil {
 		return errors.New("config target must be a non-nil pointer to struct")
 	}
@@ -45,55 +88,229 @@ func Load(target any) error {
 		return errors.New("config target must point to a struct")
 	}
 
-	errs, _ := loadStruct(elem, "")
+	l := newLoader(opts)
+	errs, _ := l.loadStruct(elem, scope{prefix: l.prefix, auto: l.auto})
+	errs = append(errs, l.checkUnknownKeys()...)
 	if len(errs) == 0 {
 		return nil
 	}
 
-	return errors.Join(errs...)
+	return &LoadError{Errors: errs}
 }
 
-func loadStruct(target reflect.Value, parentPath string) ([]error, bool) {
+// scope describes where a struct sits within the value passed to Load.
+type scope struct {
+	path   string
+	prefix string
+	secret bool
+	// optional marks structs behind a nil pointer, which are only kept (and
+	// validated) when at least one of their fields is set.
+	optional bool
+	// auto derives keys and prefixes from field names where tags omit them.
+	auto bool
+}
+
+func (s scope) child(name string) scope {
+	child := s
+	child.path = name
+	if s.path != "" {
+		child.path = s.path + "." + name
+	}
+	return child
+}
+
+func (l *loader) loadStruct(target reflect.Value, sc scope) ([]*FieldError, bool) {
 	var (
-		errs    []error
+		errs    []*FieldError
 		changed bool
 	)
 
-	targetType := target.Type()
-	for i := 0; i < target.NumField(); i++ {
+	var conds conditions
+	plan := planFor(target.Type(), sc.auto)
+	for i, fieldPlan := range plan.fields {
 		field := target.Field(i)
-		structField := targetType.Field(i)
-
-		if structField.PkgPath != "" {
-			continue
-		}
-
-		fieldPath := structField.Name
-		if parentPath != "" {
-			fieldPath = parentPath + "." + structField.Name
-		}
-
-		opts, ok, err := parseFieldOptions(structField.Tag.Get("env"))
+		spec, err := fieldPlan.resolve(sc)
 		if err != nil {
-			errs = append(errs, fmt.Errorf("field %s: %w", fieldPath, err))
+			errs = append(errs, &FieldError{Field: spec.scope.path, Kind: KindTag, Err: err})
 			continue
 		}
-		if !ok {
-			childErrs, childChanged := loadNestedField(field, fieldPath)
+
+		switch spec.kind {
+		case fieldNested:
+			childErrs, childChanged := l.loadNestedField(field, spec.scope)
 			errs = append(errs, childErrs...)
 			changed = changed || childChanged
-			continue
+		case fieldCollection:
+			childErrs, childChanged := l.loadCollectionField(field, spec.scope)
+			errs = append(errs, childErrs...)
+			changed = changed || childChanged
+		case fieldValue:
+			fieldChanged, fieldErr := l.loadValueField(field, spec, &conds)
+			if fieldErr != nil {
+				errs = append(errs, fieldErr)
+				continue
+			}
+			changed = changed || fieldChanged
 		}
+	}
+
+	errs = append(errs, l.finishStruct(sc, conds, changed, func() error {
+		return validateStruct(target)
+	})...)
+	return errs, changed
+}
+
+// loadValueField assigns a value field and records it for the struct's conditions.
+func (l *loader) loadValueField(field reflect.Value, spec fieldSpec, conds *conditions) (bool, *FieldError) {
+	value, fieldErr := l.assignField(field, spec.scope.path, spec.opts, spec.set)
+	conds.add(spec.scope.path, spec.opts, value)
+	if fieldErr != nil {
+		return false, fieldErr
+	}
+	return value.set(), nil
+}
+
+// finishStruct checks the conditions and Validate hook of a struct once its fields
+// have loaded. Structs behind a nil pointer are skipped unless a field was set.
+func (l *loader) finishStruct(sc scope, conds conditions, changed bool, validate func() error) []*FieldError {
+	if sc.optional && !changed {
+		return nil
+	}
+	errs := l.checkConditions(sc, conds)
+	if err := validate(); err != nil {
+		errs = append(errs, &FieldError{Field: sc.path, Kind: KindValidation, Err: err})
+	}
+	return errs
+}
+
+// fieldSpec is the interpretation of a struct field's tags within its scope.
+type fieldSpec struct {
+	kind fieldKind
+	// scope is the scope of the field itself; for nested structs it is the scope
+	// of their fields.
+	scope scope
+	// opts holds the resolved tag options of value fields, with prefixes applied.
+	opts fieldOptions
+	// set decodes and assigns values of value fields.
+	set setFunc
+}
+
+// fieldPlan is the interpretation of a struct field's tags that does not depend on
+// where the struct sits, so it can be compiled once per type and cached by planFor.
+type fieldPlan struct {
+	name string
+	kind fieldKind
+	// prefix is the explicit or derived envPrefix of nested structs and collections.
+	prefix string
+	// secret and auto are the nested struct options, combined with the enclosing
+	// scope's auto setting.
+	secret bool
+	auto   bool
+	// opts holds the tag options of value fields, before prefixes are applied.
+	opts fieldOptions
+	// set is the compiled setter of value fields. It is nil for plans compiled
+	// without a reflect.Type, which compile one when the field is loaded.
+	set setFunc
+	err error
+}
+
+// compileField parses the tags of structField. auto reports whether the enclosing
+// struct derives keys from field names.
+func compileField(structField reflect.StructField, auto bool) fieldPlan {
+	if structField.PkgPath != "" {
+		return fieldPlan{name: structField.Name}
+	}
+	plan := compileTags(structField.Name, structField.Tag, structField.Anonymous, shapeOf(structField.Type), auto)
+	if plan.kind == fieldValue {
+		plan.set = compileSetter(structField.Type, plan.opts)
+	}
+	return plan
+}
+
+// shapeOf classifies t for tag interpretation.
+func shapeOf(t reflect.Type) fieldShape {
+	switch {
+	case isNestedStruct(t):
+		return shapeStruct
+	case isStructCollection(t):
+		return shapeCollection
+	default:
+		return shapeValue
+	}
+}
+
+// compileTags interprets the tags of an exported field named name whose type has
+// the given shape.
+func compileTags(name string, tags reflect.StructTag, anonymous bool, shape fieldShape, auto bool) fieldPlan {
+	plan := fieldPlan{name: name}
 
-		fieldChanged, err := assignField(field, fieldPath, opts)
+	tag := tags.Get("env")
+	if strings.TrimSpace(tag) == "-" {
+		return plan
+	}
+
+	envPrefix, hasPrefix := tags.Lookup("envPrefix")
+	if hasPrefix && shape == shapeValue {
+		plan.err = errors.New("envPrefix requires a nested struct, struct slice or struct map field")
+		return plan
+	}
+
+	if shape != shapeValue {
+		nested, ok, err := parseNestedOptions(tag)
 		if err != nil {
-			errs = append(errs, err)
-			continue
+			plan.err = err
+			return plan
+		}
+		nestedAuto := auto || nested.auto
+		if ok && nestedAuto && !hasPrefix && !anonymous {
+			envPrefix, hasPrefix = deriveKey(name)+"_", true
+		}
+		if ok && (shape == shapeStruct || hasPrefix) {
+			plan.kind = fieldNested
+			if shape == shapeCollection {
+				plan.kind = fieldCollection
+			}
+			plan.prefix = envPrefix
+			plan.secret = nested.secret
+			plan.auto = nestedAuto
+			return plan
 		}
-		changed = changed || fieldChanged
 	}
 
-	return errs, changed
+	opts, ok, err := parseFieldOptions(autoTag(tag, name, auto))
+	if err != nil {
+		plan.err = err
+		return plan
+	}
+	if !ok {
+		return plan
+	}
+
+	plan.kind = fieldValue
+	plan.opts = opts
+	return plan
+}
+
+// resolve places the field in a struct at sc. On error, the returned spec still
+// carries the field's scope.
+func (p fieldPlan) resolve(sc scope) (fieldSpec, error) {
+	spec := fieldSpec{kind: p.kind, scope: sc.child(p.name)}
+	if p.err != nil {
+		spec.kind = fieldIgnored
+		return spec, p.err
+	}
+
+	switch p.kind {
+	case fieldNested, fieldCollection:
+		spec.scope.prefix += p.prefix
+		spec.scope.secret = spec.scope.secret || p.secret
+		spec.scope.auto = p.auto
+	case fieldValue:
+		spec.set = p.set
+		spec.opts = p.opts.withPrefix(sc.prefix)
+		spec.opts.secret = spec.opts.secret || sc.secret
+	}
+	return spec, nil
 }
 
 type fieldOptions struct {
@@ -107,6 +324,69 @@ type fieldOptions struct {
 	layout     string
 	oneOf      []string
 	format     string
+	secret     bool
+	file       bool
+	// noFileKey turns off the KEY_FILE companion because another field of the
+	// same struct declares that key.
+	noFileKey bool
+	expand    bool
+	aliases   []string
+	min       string
+	max       string
+	length    int
+	minLen    int
+	maxLen    int
+	pattern   *regexp.Regexp
+	// requiredIfKey and requiredIfValues make the field required when the variable
+	// requiredIfKey holds one of requiredIfValues.
+	requiredIfKey    string
+	requiredIfValues []string
+	// requiredWith makes the field required when any of these variables is set.
+	requiredWith []string
+	group        string
+	groupMode    string
+}
+
+// nestedOptions holds the options accepted on an untagged-key nested struct field,
+// such as `env:",secret"`.
+type nestedOptions struct {
+	secret bool
+	auto   bool
+}
+
+// parseNestedOptions parses the env tag of a nested struct field. It reports false
+// when the tag names a key, leaving the field to the regular tag rules.
+func parseNestedOptions(tag string) (nestedOptions, bool, error) {
+	if tag == "" {
+		return nestedOptions{}, true, nil
+	}
+
+	parts := splitTag(tag)
+	if strings.TrimSpace(parts[0]) != "" {
+		return nestedOptions{}, false, nil
+	}
+
+	var opts nestedOptions
+	for _, raw := range parts[1:] {
+		switch part := strings.TrimSpace(raw); part {
+		case "":
+		case "secret", "sensitive":
+			opts.secret = true
+		case "auto":
+			opts.auto = true
+		default:
+			return nestedOptions{}, false, fmt.Errorf("unsupported env option %q for nested struct", part)
+		}
+	}
+	return opts, true, nil
+}
+
+func (o fieldOptions) parseOptions() ParseOptions {
+	return ParseOptions{
+		Key:    o.key,
+		Layout: o.layout,
+		Format: o.format,
+	}
 }
 
 func parseFieldOptions(tag string) (fieldOptions, bool, error) {
@@ -127,6 +407,9 @@ func parseFieldOptions(tag string) (fieldOptions, bool, error) {
 		sep:      ",",
 		entrySep: ",",
 		kvSep:    "=",
+		length:   -1,
+		minLen:   -1,
+		maxLen:   -1,
 	}
 
 	for _, raw := range parts[1:] {
@@ -138,6 +421,14 @@ func parseFieldOptions(tag string) (fieldOptions, bool, error) {
 		switch {
 		case part == "required":
 			opts.required = true
+		case part == "secret", part == "sensitive":
+			opts.secret = true
+		case part == "file":
+			opts.file = true
+		case part == "expand":
+			opts.expand = true
+		case part == "auto":
+			// The key has already been derived by autoTag.
 		case strings.HasPrefix(part, "default="):
 			opts.hasDefault = true
 			opts.defaultVal = strings.TrimPrefix(part, "default=")
@@ -156,6 +447,65 @@ func parseFieldOptions(tag string) (fieldOptions, bool, error) {
 			}
 		case strings.HasPrefix(part, "format="):
 			opts.format = strings.TrimPrefix(part, "format=")
+		case strings.HasPrefix(part, "alias="):
+			for _, alias := range strings.Split(strings.TrimPrefix(part, "alias="), "|") {
+				alias = strings.TrimSpace(alias)
+				if alias == "" {
+					return fieldOptions{}, false, fmt.Errorf("invalid env option %q: empty alias", part)
+				}
+				opts.aliases = append(opts.aliases, alias)
+			}
+		case strings.HasPrefix(part, "min="):
+			opts.min = strings.TrimPrefix(part, "min=")
+		case strings.HasPrefix(part, "max="):
+			opts.max = strings.TrimPrefix(part, "max=")
+		case strings.HasPrefix(part, "len="):
+			value, err := parseLengthOption(part, "len=")
+			if err != nil {
+				return fieldOptions{}, false, err
+			}
+			opts.length = value
+		case strings.HasPrefix(part, "minlen="):
+			value, err := parseLengthOption(part, "minlen=")
+			if err != nil {
+				return fieldOptions{}, false, err
+			}
+			opts.minLen = value
+		case strings.HasPrefix(part, "maxlen="):
+			value, err := parseLengthOption(part, "maxlen=")
+			if err != nil {
+				return fieldOptions{}, false, err
+			}
+			opts.maxLen = value
+		case strings.HasPrefix(part, "pattern="):
+			pattern, err := regexp.Compile(strings.TrimPrefix(part, "pattern="))
+			if err != nil {
+				return fieldOptions{}, false, fmt.Errorf("invalid env option %q: %w", part, err)
+			}
+			opts.pattern = pattern
+		case strings.HasPrefix(part, "required_if="):
+			key, values, ok := strings.Cut(strings.TrimPrefix(part, "required_if="), ":")
+			if !ok || strings.TrimSpace(key) == "" {
+				return fieldOptions{}, false, fmt.Errorf("invalid env option %q: expected KEY:value", part)
+			}
+			opts.requiredIfKey = strings.TrimSpace(key)
+			opts.requiredIfValues = strings.Split(values, "|")
+		case strings.HasPrefix(part, "required_with="):
+			for _, key := range strings.Split(strings.TrimPrefix(part, "required_with="), "|") {
+				key = strings.TrimSpace(key)
+				if key == "" {
+					return fieldOptions{}, false, fmt.Errorf("invalid env option %q: empty key", part)
+				}
+				opts.requiredWith = append(opts.requiredWith, key)
+			}
+		case strings.HasPrefix(part, "group="):
+			name, mode, _ := strings.Cut(strings.TrimPrefix(part, "group="), ",")
+			name, mode = strings.TrimSpace(name), strings.TrimSpace(mode)
+			if name == "" || !isGroupMode(mode) {
+				return fieldOptions{}, false, fmt.Errorf("invalid env option %q: expected group=NAME,exactlyone|atmostone|atleastone", part)
+			}
+			opts.group = name
+			opts.groupMode = mode
 		default:
 			return fieldOptions{}, false, fmt.Errorf("unsupported env option %q", part)
 		}
@@ -164,6 +514,56 @@ func parseFieldOptions(tag string) (fieldOptions, bool, error) {
 	return opts, true, nil
 }
 
+func parseLengthOption(part, prefix string) (int, error) {
+	value, err := strconv.Atoi(strings.TrimPrefix(part, prefix))
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid env option %q: expected a non-negative integer", part)
+	}
+	return value, nil
+}
+
+// withPrefix returns o with prefix prepended to its key and aliases.
+func (o fieldOptions) withPrefix(prefix string) fieldOptions {
+	if prefix == "" {
+		return o
+	}
+	o.key = prefix + o.key
+	o.aliases = prefixAll(prefix, o.aliases)
+	if o.requiredIfKey != "" {
+		o.requiredIfKey = prefix + o.requiredIfKey
+	}
+	o.requiredWith = prefixAll(prefix, o.requiredWith)
+	return o
+}
+
+func prefixAll(prefix string, keys []string) []string {
+	var prefixed []string
+	for _, key := range keys {
+		prefixed = append(prefixed, prefix+key)
+	}
+	return prefixed
+}
+
+// elementOptions returns the options applied to each slice element, map key and map
+// value. Length and range bounds constrain the collection itself, not its entries.
+func (o fieldOptions) elementOptions() fieldOptions {
+	o.min, o.max = "", ""
+	o.length, o.minLen, o.maxLen = -1, -1, -1
+	return o
+}
+
+// keyOptions returns the options applied to each map key. Value constraints such as
+// oneof and pattern only apply to map values.
+func (o fieldOptions) keyOptions() fieldOptions {
+	o = o.elementOptions()
+	o.oneOf = nil
+	o.pattern = nil
+	if isEncodingFormat(o.format) {
+		o.format = ""
+	}
+	return o
+}
+
 func splitTag(tag string) []string {
 	var (
 		parts         []string
@@ -197,7 +597,8 @@ func splitTag(tag string) []string {
 
 func isTagOption(part string) bool {
 	part = strings.TrimSpace(part)
-	if part == "required" {
+	switch part {
+	case "required", "secret", "sensitive", "file", "expand", "auto":
 		return true
 	}
 	for _, prefix := range []string{
@@ -208,6 +609,16 @@ func isTagOption(part string) bool {
 		"layout=",
 		"oneof=",
 		"format=",
+		"alias=",
+		"min=",
+		"max=",
+		"len=",
+		"minlen=",
+		"maxlen=",
+		"pattern=",
+		"required_if=",
+		"required_with=",
+		"group=",
 	} {
 		if strings.HasPrefix(part, prefix) {
 			return true
@@ -216,149 +627,279 @@ func isTagOption(part string) bool {
 	return false
 }
 
-func loadNestedField(field reflect.Value, fieldPath string) ([]error, bool) {
+func (l *loader) loadNestedField(field reflect.Value, sc scope) ([]*FieldError, bool) {
 	fieldType := field.Type()
 
 	switch {
 	case fieldType.Kind() == reflect.Struct && shouldRecurseIntoStruct(fieldType):
-		return loadStruct(field, fieldPath)
+		return l.loadStruct(field, sc)
 	case fieldType.Kind() == reflect.Pointer && fieldType.Elem().Kind() == reflect.Struct && shouldRecurseIntoStruct(fieldType.Elem()):
 		if field.IsNil() {
 			child := reflect.New(fieldType.Elem())
-			errs, changed := loadStruct(child.Elem(), fieldPath)
+			sc.optional = true
+			errs, changed := l.loadStruct(child.Elem(), sc)
 			if changed {
 				field.Set(child)
 			}
 			return errs, changed
 		}
-		return loadStruct(field.Elem(), fieldPath)
+		return l.loadStruct(field.Elem(), sc)
 	default:
 		return nil, false
 	}
 }
 
+// isNestedStruct reports whether fields of type t are traversed as nested structs.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && shouldRecurseIntoStruct(t)
+}
+
 func shouldRecurseIntoStruct(fieldType reflect.Type) bool {
-	return fieldType != timeTimeType && fieldType != urlType
+	return fieldType != timeTimeType && fieldType != urlType && !isOptional(fieldType) && !isUnmarshaler(reflect.PointerTo(fieldType))
 }
 
-func assignField(field reflect.Value, fieldName string, opts fieldOptions) (bool, error) {
-	raw, ok := os.LookupEnv(opts.key)
-	if !ok {
-		if opts.hasDefault {
-			raw = opts.defaultVal
-			ok = true
-		} else if opts.required {
-			return false, fmt.Errorf("field %s: environment variable %q is required", fieldName, opts.key)
-		} else {
-			return false, nil
-		}
+// assignField resolves the value of a field and assigns it with set, or with a
+// setter compiled for the field's type when set is nil.
+func (l *loader) assignField(field reflect.Value, fieldName string, opts fieldOptions, set setFunc) (resolvedValue, *FieldError) {
+	value, action, fieldErr := l.resolveField(fieldName, opts, field.IsZero())
+	switch action {
+	case fieldKeep:
+		return l.keepField(fieldName, opts, existingValue(field)), nil
+	case fieldSkip:
+		return value, fieldErr
 	}
 
 	if !field.CanSet() {
-		return false, fmt.Errorf("field %s: cannot set value", fieldName)
+		return value, &FieldError{Field: fieldName, Kind: KindTag, Err: errors.New("cannot set value")}
 	}
 
-	if err := setValue(field, raw, opts); err != nil {
-		return false, fmt.Errorf("field %s: env %q value %q: %w", fieldName, opts.key, raw, err)
+	if set == nil {
+		set = compileSetter(field.Type(), opts)
 	}
+	if err := set(l, field, value.raw, opts); err != nil {
+		return value, l.decodeError(fieldName, opts, value, field.Type().String(), err)
+	}
+	return value, nil
+}
+
+// fieldAction is what becomes of a field once its value is resolved.
+type fieldAction int
 
-	return true, nil
+const (
+	// fieldSkip leaves the field as it is, because no value was found or it could
+	// not be read.
+	fieldSkip fieldAction = iota
+	// fieldDecode assigns the resolved value.
+	fieldDecode
+	// fieldKeep keeps the value the field held before Load.
+	fieldKeep
+)
+
+// resolveField finds the value of a field and decides what to do with it. zero
+// reports whether the field holds its type's zero value, which decides whether a
+// merge mode keeps it. The value is recorded in the report unless the field is
+// kept; the caller records kept fields with keepField.
+func (l *loader) resolveField(fieldName string, opts fieldOptions, zero bool) (resolvedValue, fieldAction, *FieldError) {
+	l.declare(opts)
+	// Under WithFillZero a kept field ignores the source, so it is not read at all:
+	// no KEY_FILE is opened and no alias warning is given. Declaring its keys is
+	// enough for strict mode.
+	if l.merge == mergeFillZero && !zero {
+		return resolvedValue{}, fieldKeep, nil
+	}
+	value, ok, fieldErr := l.resolveValue(fieldName, opts)
+	if fieldErr != nil {
+		return value, fieldSkip, fieldErr
+	}
+	if l.keepsExisting(zero, value) {
+		return value, fieldKeep, nil
+	}
+	l.record(fieldName, opts, value)
+	if !ok {
+		if opts.required {
+			return value, fieldSkip, &FieldError{Field: fieldName, Key: opts.key, Kind: KindMissing, Err: ErrRequired}
+		}
+		return value, fieldSkip, nil
+	}
+	return value, fieldDecode, nil
 }
 
-func setValue(field reflect.Value, raw string, opts fieldOptions) error {
-	fieldType := field.Type()
+// keepField records that a field keeps existing, the value it held before Load.
+func (l *loader) keepField(fieldName string, opts fieldOptions, existing resolvedValue) resolvedValue {
+	existing.key = opts.key
+	l.record(fieldName, opts, existing)
+	return existing
+}
 
+// decodeError describes err, returned while decoding value into a field of the
+// named type. The value is left out when it may hold a secret.
+func (l *loader) decodeError(fieldName string, opts fieldOptions, value resolvedValue, typeName string, err error) *FieldError {
+	fieldErr := &FieldError{Field: fieldName, Key: value.key, Value: value.display(), File: value.file, Kind: classifyError(err), Err: err}
 	switch {
-	case fieldType == timeDurationType:
-		value, err := time.ParseDuration(raw)
-		if err != nil {
-			return err
-		}
-		field.SetInt(int64(value))
-		return nil
-	case fieldType == timeTimeType:
-		if opts.layout == "" {
-			return errors.New("time.Time fields require layout")
-		}
-		value, err := time.Parse(opts.layout, raw)
-		if err != nil {
-			return err
-		}
-		field.Set(reflect.ValueOf(value))
-		return nil
-	case fieldType == urlType:
-		value, err := parseURL(raw)
-		if err != nil {
-			return err
-		}
-		field.Set(reflect.ValueOf(*value))
-		return nil
-	case fieldType.Kind() == reflect.Pointer && fieldType.Elem() == urlType:
-		value, err := parseURL(raw)
-		if err != nil {
-			return err
-		}
-		ptr := reflect.New(urlType)
-		ptr.Elem().Set(reflect.ValueOf(*value))
-		field.Set(ptr)
-		return nil
-	case fieldType.Kind() == reflect.Struct:
-		return errors.New("nested structs are not supported")
-	case fieldType.Kind() == reflect.String:
-		if len(opts.oneOf) > 0 && !slices.Contains(opts.oneOf, raw) {
-			return fmt.Errorf("value is not in enum %v", opts.oneOf)
-		}
-		field.SetString(raw)
-		return nil
-	case fieldType.Kind() == reflect.Bool:
-		value, err := strconv.ParseBool(raw)
-		if err != nil {
-			return err
+	case opts.secret || l.redact || value.file != "":
+		fieldErr.Value = ""
+		fieldErr.Redacted = true
+		fieldErr.Err = &redactedError{err: err, typeName: typeName}
+	case value.unexpanded != "":
+		// Parse errors quote the expanded value, which may include secrets.
+		fieldErr.Err = &redactedError{err: err, typeName: typeName}
+	}
+	return fieldErr
+}
+
+// resolvedValue is the raw value chosen for a field and where it was found.
+type resolvedValue struct {
+	raw string
+	// key is the variable that supplied raw, or that named the file it was read from.
+	key string
+	// file is the path raw was read from, if any.
+	file   string
+	source Source
+	// unexpanded is the value as found, before variable references in it were
+	// expanded. It is empty when nothing was expanded.
+	unexpanded string
+}
+
+// display returns the value shown in errors and the report. Expanded references
+// may have read secret variables, so the text from before expansion is shown.
+func (v resolvedValue) display() string {
+	if v.unexpanded != "" {
+		return v.unexpanded
+	}
+	return v.raw
+}
+
+// set reports whether the field has a value, even one that then failed to load.
+func (v resolvedValue) set() bool {
+	return v.source != SourceUnset
+}
+
+// resolveValue finds the raw value for a field: the variable itself, then the file
+// named by KEY_FILE, then each alias, then the default. Variable references are
+// expanded before `file` fields read the file their value names; file contents are
+// never expanded.
+func (l *loader) resolveValue(fieldName string, opts fieldOptions) (resolvedValue, bool, *FieldError) {
+	value, found, fieldErr := l.lookupValue(fieldName, opts)
+	if fieldErr != nil {
+		return value, false, fieldErr
+	}
+	if value.file != "" {
+		return value, true, nil
+	}
+	if !found {
+		if !opts.hasDefault {
+			return value, false, nil
 		}
-		field.SetBool(value)
-		return nil
-	case fieldType.Kind() == reflect.Int, fieldType.Kind() == reflect.Int8, fieldType.Kind() == reflect.Int16, fieldType.Kind() == reflect.Int32, fieldType.Kind() == reflect.Int64:
-		value, err := parseSignedInteger(raw, fieldType.Bits(), opts.format)
+		value.raw = opts.defaultVal
+		value.source = SourceDefault
+	}
+
+	if opts.expand || l.expand {
+		expanded, err := l.expandValue(value.raw)
 		if err != nil {
-			return err
+			fieldErr := &FieldError{Field: fieldName, Key: value.key, Value: value.raw, Kind: KindParse, Err: err}
+			if opts.secret || l.redact {
+				fieldErr.Value = ""
+				fieldErr.Redacted = true
+			}
+			return value, false, fieldErr
 		}
-		field.SetInt(value)
-		return nil
-	case fieldType.Kind() == reflect.Uint, fieldType.Kind() == reflect.Uint8, fieldType.Kind() == reflect.Uint16, fieldType.Kind() == reflect.Uint32, fieldType.Kind() == reflect.Uint64:
-		value, err := parseUnsignedInteger(raw, fieldType.Bits())
-		if err != nil {
-			return err
+		if expanded != value.raw {
+			value.unexpanded = value.raw
 		}
-		field.SetUint(value)
-		return nil
-	case fieldType.Kind() == reflect.Float32, fieldType.Kind() == reflect.Float64:
-		value, err := strconv.ParseFloat(raw, fieldType.Bits())
+		value.raw = expanded
+	}
+
+	if opts.file {
+		content, err := readValueFile(value.raw, l.maxFileSize)
 		if err != nil {
-			return err
+			return value, false, &FieldError{Field: fieldName, Key: value.key, Value: value.display(), File: value.raw, Kind: KindFile, Err: err}
 		}
-		field.SetFloat(value)
-		return nil
-	case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.String:
-		value, err := parseStringSlice(raw, opts.sep)
-		if err != nil {
-			return err
+		value.file = value.raw
+		value.raw = content
+		value.source = SourceFile
+	}
+	return value, true, nil
+}
+
+// lookupValue consults the source for the field's key, its KEY_FILE companion and
+// its aliases, in that order. Use of an alias is reported as a warning.
+func (l *loader) lookupValue(fieldName string, opts fieldOptions) (resolvedValue, bool, *FieldError) {
+	if raw, ok := l.lookup(opts.key); ok {
+		return resolvedValue{raw: raw, key: opts.key, source: SourceEnv}, true, nil
+	}
+
+	if !opts.file && !opts.noFileKey {
+		fileKey := opts.key + fileKeySuffix
+		if path, ok := l.lookup(fileKey); ok {
+			content, err := readValueFile(path, l.maxFileSize)
+			if err != nil {
+				return resolvedValue{key: fileKey}, false, &FieldError{Field: fieldName, Key: fileKey, Value: path, File: path, Kind: KindFile, Err: err}
+			}
+			return resolvedValue{raw: content, key: fileKey, file: path, source: SourceFile}, true, nil
 		}
-		field.Set(reflect.ValueOf(value))
-		return nil
-	case fieldType.Kind() == reflect.Map && fieldType.Key().Kind() == reflect.String && fieldType.Elem().Kind() == reflect.String:
-		value, err := parseMap(raw, opts.kvSep, opts.entrySep)
-		if err != nil {
-			return err
+	}
+
+	for _, alias := range opts.aliases {
+		if raw, ok := l.lookup(alias); ok {
+			l.warn(Warning{
+				Field:   fieldName,
+				Key:     alias,
+				Message: fmt.Sprintf("environment variable %q is deprecated; use %q instead", alias, opts.key),
+			})
+			return resolvedValue{raw: raw, key: alias, source: SourceAlias}, true, nil
 		}
-		field.Set(reflect.ValueOf(value))
-		return nil
+	}
+
+	return resolvedValue{key: opts.key, source: SourceUnset}, false, nil
+}
+
+// classifyError maps an error returned by a field setter to the FieldError kind it represents.
+func classifyError(err error) ErrorKind {
+	switch {
+	case errors.Is(err, ErrValidation):
+		return KindValidation
+	case errors.Is(err, ErrInvalidTag):
+		return KindTag
+	default:
+		return KindParse
+	}
+}
+
+// wrapElementError prefixes err with its position in a collection. Tag errors
+// describe the element type rather than the value and are returned unchanged.
+func wrapElementError(err error, format string, args ...any) error {
+	if errors.Is(err, ErrInvalidTag) {
+		return err
+	}
+	return fmt.Errorf(format+": %w", append(args, err)...)
+}
+
+// isUnmarshaler reports whether values of type t can decode themselves from text.
+func isUnmarshaler(t reflect.Type) bool {
+	return t.Implements(textUnmarshalerType) || t.Implements(binaryUnmarshalerType) || t.Implements(flagValueType)
+}
+
+// unmarshalValue decodes raw into target, preferring encoding.TextUnmarshaler over
+// encoding.BinaryUnmarshaler over flag.Value.
+func unmarshalValue(target any, raw string) error {
+	switch value := target.(type) {
+	case encoding.TextUnmarshaler:
+		return value.UnmarshalText([]byte(raw))
+	case encoding.BinaryUnmarshaler:
+		return value.UnmarshalBinary([]byte(raw))
+	case flag.Value:
+		return value.Set(raw)
 	default:
-		return fmt.Errorf("unsupported field type %s", fieldType)
+		return fmt.Errorf("unsupported field type %T", target)
 	}
 }
 
 func parseSignedInteger(raw string, bits int, format string) (int64, error) {
-	if format == "bytes" {
+	if format == formatBytes {
 		value, err := parseBytes(raw)
 		if err != nil {
 			return 0, err
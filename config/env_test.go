@@ -1,6 +1,9 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"net/url"
 	"reflect"
 	"strings"
@@ -229,3 +232,258 @@ func TestLoadAggregatesFieldErrors(t *testing.T) {
 		}
 	}
 }
+
+type logLevel int
+
+func (l *logLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "debug":
+		*l = 0
+	case "info":
+		*l = 1
+	case "error":
+		*l = 2
+	default:
+		return errors.New("unknown log level")
+	}
+	return nil
+}
+
+type regionCode struct {
+	value string
+}
+
+func (r *regionCode) UnmarshalBinary(data []byte) error {
+	r.value = strings.ToUpper(string(data))
+	return nil
+}
+
+type semver struct {
+	major, minor, patch int
+}
+
+func (s *semver) String() string {
+	return fmt.Sprintf("%d.%d.%d", s.major, s.minor, s.patch)
+}
+
+func (s *semver) Set(raw string) error {
+	_, err := fmt.Sscanf(raw, "%d.%d.%d", &s.major, &s.minor, &s.patch)
+	return err
+}
+
+func TestLoadDecodesUnmarshalerFields(t *testing.T) {
+	type testConfig struct {
+		Level     logLevel   `env:"LEVEL,default=info"`
+		LevelPtr  *logLevel  `env:"LEVEL_PTR"`
+		Region    regionCode `env:"REGION"`
+		Version   semver     `env:"VERSION"`
+		VersionFn *semver    `env:"VERSION"`
+		Untagged  regionCode // untagged unmarshaler structs are not traversed
+		Addr      net.IP     `env:"ADDR"`
+	}
+
+	t.Setenv("LEVEL_PTR", "error")
+	t.Setenv("REGION", "us-west")
+	t.Setenv("VERSION", "1.2.3")
+	t.Setenv("ADDR", "10.0.0.1")
+
+	var cfg testConfig
+	if err := Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Level != 1 {
+		t.Fatalf("expected default level 1, got %d", cfg.Level)
+	}
+	if cfg.LevelPtr == nil || *cfg.LevelPtr != 2 {
+		t.Fatalf("expected pointer level 2, got %v", cfg.LevelPtr)
+	}
+	if cfg.Region.value != "US-WEST" {
+		t.Fatalf("expected region US-WEST, got %q", cfg.Region.value)
+	}
+	if got := cfg.Version.String(); got != "1.2.3" {
+		t.Fatalf("expected version 1.2.3, got %s", got)
+	}
+	if cfg.VersionFn == nil || cfg.VersionFn.String() != "1.2.3" {
+		t.Fatalf("expected pointer version 1.2.3, got %v", cfg.VersionFn)
+	}
+	if !cfg.Addr.Equal(net.ParseIP("10.0.0.1")) {
+		t.Fatalf("expected addr 10.0.0.1, got %v", cfg.Addr)
+	}
+
+	t.Setenv("LEVEL", "verbose")
+	err := Load(&cfg)
+	if err == nil {
+		t.Fatal("expected unmarshal error")
+	}
+	if got := err.Error(); !strings.Contains(got, `env "LEVEL" value "verbose": unknown log level`) {
+		t.Fatalf("expected unmarshal error for LEVEL, got %q", got)
+	}
+}
+
+func TestLoadParsesTypedSlicesAndMaps(t *testing.T) {
+	type testConfig struct {
+		Ports     []int                    `env:"PORTS"`
+		Timeouts  []time.Duration          `env:"TIMEOUTS,sep=|"`
+		Upstreams []*url.URL               `env:"UPSTREAMS"`
+		Sizes     []int64                  `env:"SIZES,format=bytes"`
+		Modes     []string                 `env:"MODES,oneof=dev|prod"`
+		Weights   map[string]int           `env:"WEIGHTS"`
+		Deadlines map[string]time.Duration `env:"DEADLINES,entrysep=;,kvsep=:"`
+		ByCode    map[int]string           `env:"BY_CODE"`
+	}
+
+	t.Setenv("PORTS", "80, 443")
+	t.Setenv("TIMEOUTS", "1s|250ms")
+	t.Setenv("UPSTREAMS", "https://a.example.com,https://b.example.com")
+	t.Setenv("SIZES", "1KiB,2MiB")
+	t.Setenv("MODES", "dev,prod")
+	t.Setenv("WEIGHTS", "a=1,b=2")
+	t.Setenv("DEADLINES", "read:5s;write:10s")
+	t.Setenv("BY_CODE", "200=ok,404=missing")
+
+	var cfg testConfig
+	if err := Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := []int{80, 443}; !reflect.DeepEqual(cfg.Ports, want) {
+		t.Fatalf("expected ports %v, got %v", want, cfg.Ports)
+	}
+	if want := []time.Duration{time.Second, 250 * time.Millisecond}; !reflect.DeepEqual(cfg.Timeouts, want) {
+		t.Fatalf("expected timeouts %v, got %v", want, cfg.Timeouts)
+	}
+	if len(cfg.Upstreams) != 2 || cfg.Upstreams[1].Host != "b.example.com" {
+		t.Fatalf("expected two upstream urls, got %v", cfg.Upstreams)
+	}
+	if want := []int64{1024, 2 * 1024 * 1024}; !reflect.DeepEqual(cfg.Sizes, want) {
+		t.Fatalf("expected sizes %v, got %v", want, cfg.Sizes)
+	}
+	if want := []string{"dev", "prod"}; !reflect.DeepEqual(cfg.Modes, want) {
+		t.Fatalf("expected modes %v, got %v", want, cfg.Modes)
+	}
+	if want := map[string]int{"a": 1, "b": 2}; !reflect.DeepEqual(cfg.Weights, want) {
+		t.Fatalf("expected weights %v, got %v", want, cfg.Weights)
+	}
+	if want := map[string]time.Duration{"read": 5 * time.Second, "write": 10 * time.Second}; !reflect.DeepEqual(cfg.Deadlines, want) {
+		t.Fatalf("expected deadlines %v, got %v", want, cfg.Deadlines)
+	}
+	if want := map[int]string{200: "ok", 404: "missing"}; !reflect.DeepEqual(cfg.ByCode, want) {
+		t.Fatalf("expected by-code map %v, got %v", want, cfg.ByCode)
+	}
+}
+
+func TestLoadReportsTypedCollectionElementErrors(t *testing.T) {
+	type testConfig struct {
+		Ports   []int          `env:"PORTS"`
+		Weights map[string]int `env:"WEIGHTS"`
+		Modes   []string       `env:"MODES,oneof=dev|prod"`
+	}
+
+	t.Setenv("PORTS", "80,http")
+	t.Setenv("WEIGHTS", "a=1,b=heavy")
+	t.Setenv("MODES", "dev,qa")
+
+	var cfg testConfig
+	err := Load(&cfg)
+	if err == nil {
+		t.Fatal("expected element errors")
+	}
+
+	got := err.Error()
+	for _, want := range []string{
+		`env "PORTS" value "80,http": element 1: strconv.ParseInt`,
+		`env "WEIGHTS" value "a=1,b=heavy": key "b": strconv.ParseInt`,
+		`env "MODES" value "dev,qa": element 1: value is not in enum [dev prod]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected error to contain %q, got %q", want, got)
+		}
+	}
+}
+
+func TestLoadAppliesNestedPrefixes(t *testing.T) {
+	type dbConfig struct {
+		Host string `env:"HOST,required"`
+		Port int    `env:"PORT,default=5432"`
+	}
+	type storageConfig struct {
+		Primary dbConfig  `envPrefix:"PRIMARY_"`
+		Replica *dbConfig `envPrefix:"REPLICA_"`
+	}
+	type appConfig struct {
+		Name    string        `env:"NAME"`
+		Storage storageConfig `envPrefix:"DB_"`
+	}
+
+	source := MapSource{
+		"APP_NAME":                "api",
+		"APP_DB_PRIMARY_HOST":     "primary.internal",
+		"APP_DB_REPLICA_HOST":     "replica.internal",
+		"APP_DB_REPLICA_PORT":     "6432",
+		"DB_PRIMARY_HOST":         "ignored",
+		"APP_DB_PRIMARY_UNTAGGED": "ignored",
+	}
+
+	var cfg appConfig
+	if err := LoadFrom(&cfg, source, WithPrefix("APP_")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Name != "api" {
+		t.Fatalf("expected name api, got %q", cfg.Name)
+	}
+	if cfg.Storage.Primary.Host != "primary.internal" || cfg.Storage.Primary.Port != 5432 {
+		t.Fatalf("expected primary config to load, got %+v", cfg.Storage.Primary)
+	}
+	if cfg.Storage.Replica == nil || cfg.Storage.Replica.Host != "replica.internal" || cfg.Storage.Replica.Port != 6432 {
+		t.Fatalf("expected replica config to load, got %+v", cfg.Storage.Replica)
+	}
+
+	err := LoadFrom(&appConfig{}, MapSource{}, WithPrefix("APP_"))
+	if err == nil {
+		t.Fatal("expected required errors")
+	}
+	if got := err.Error(); !strings.Contains(got, `field Storage.Primary.Host: environment variable "APP_DB_PRIMARY_HOST" is required`) {
+		t.Fatalf("expected prefixed key in error, got %q", got)
+	}
+}
+
+func TestLoadFallsBackToDeprecatedAliases(t *testing.T) {
+	type testConfig struct {
+		Port    int    `env:"HTTP_PORT,alias=PORT|APP_PORT,default=8080"`
+		Host    string `env:"HTTP_HOST,alias=HOST"`
+		Missing string `env:"MISSING,alias=OLD_MISSING,required"`
+	}
+
+	source := MapSource{
+		"APP_PORT":  "9090",
+		"HTTP_HOST": "0.0.0.0",
+		"HOST":      "ignored",
+	}
+
+	var warnings []Warning
+	var cfg testConfig
+	err := LoadFrom(&cfg, source, WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, w)
+	}))
+	if err == nil || !strings.Contains(err.Error(), `environment variable "MISSING" is required`) {
+		t.Fatalf("expected required error naming the primary key, got %v", err)
+	}
+
+	if cfg.Port != 9090 {
+		t.Fatalf("expected port from alias, got %d", cfg.Port)
+	}
+	if cfg.Host != "0.0.0.0" {
+		t.Fatalf("expected primary key to win over alias, got %q", cfg.Host)
+	}
+
+	want := []Warning{{
+		Field:   "Port",
+		Key:     "APP_PORT",
+		Message: `environment variable "APP_PORT" is deprecated; use "HTTP_PORT" instead`,
+	}}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Fatalf("expected warnings %+v, got %+v", want, warnings)
+	}
+}
@@ -0,0 +1,332 @@
+// Package envchainrt is the runtime support for loaders generated by envchain-gen
+// and for tools that inspect env tags the way config.Load interprets them. The
+// loading rules themselves live in package config. Generated code calls the
+// declarations here to look values up, check them and report errors, and parses
+// values of built-in types itself. Session, Struct, Collection and the decoding
+// helpers are only meant to be called by generated code, and change together
+// with envchain-gen.
+package envchainrt
+
+import (
+	"net/url"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/stuft2/envchain/config"
+	"github.com/stuft2/envchain/config/internal/bridge"
+)
+
+// FieldKind classifies how config.Load treats a struct field.
+type FieldKind int
+
+const (
+	// FieldIgnored fields are not loaded.
+	FieldIgnored FieldKind = iota
+	// FieldNested fields are structs, or pointers to structs, whose fields are loaded.
+	FieldNested
+	// FieldCollection fields are slices or string-keyed maps of structs loaded from
+	// indexed or keyed prefixes.
+	FieldCollection
+	// FieldValue fields are loaded from a single variable.
+	FieldValue
+)
+
+// FieldShape describes a field's type as far as tag interpretation is concerned.
+type FieldShape int
+
+const (
+	// ShapeValue is any type that is not a nested struct or struct collection.
+	ShapeValue FieldShape = iota
+	// ShapeStruct is a struct, or pointer to a struct, that config.Load recurses
+	// into: not time.Time, url.URL, config.Optional or a type that decodes itself
+	// from text.
+	ShapeStruct
+	// ShapeCollection is a slice, or a map with string keys, of ShapeStruct elements.
+	ShapeCollection
+)
+
+// Field is a struct field whose tags have been compiled by CompileField.
+type Field struct {
+	f bridge.Field
+
+	derive       sync.Once
+	element, key *Field
+}
+
+// FieldInfo describes a compiled field.
+type FieldInfo struct {
+	Kind FieldKind
+	// Prefix is the envPrefix, explicit or derived from the field name, of nested
+	// and collection fields.
+	Prefix string
+	// Secret and Auto are the options nested and collection fields pass on to the
+	// fields beneath them.
+	Secret bool
+	Auto   bool
+	// Tag holds the parsed env tag of value fields.
+	Tag FieldTag
+}
+
+// FieldTag is the parsed env tag of a value field. Keys are relative to the
+// enclosing prefixes.
+type FieldTag struct {
+	Key        string
+	Aliases    []string
+	Required   bool
+	Default    string
+	HasDefault bool
+	OneOf      []string
+	Layout     string
+	Format     string
+	Secret     bool
+	File       bool
+}
+
+// CompileField interprets the tags of an exported struct field named name, whose
+// type has the given shape, exactly as config.Load does. auto reports whether the
+// enclosing struct derives keys from field names. Invalid tags are reported by
+// Err, and by any loader that uses the field.
+func CompileField(name string, tag reflect.StructTag, anonymous bool, shape FieldShape, auto bool) *Field {
+	return &Field{f: bridge.CompileField(name, tag, anonymous, int(shape), auto)}
+}
+
+// Err returns the error found in the field's tags, if any.
+func (f *Field) Err() error {
+	return f.f.Err()
+}
+
+// Info describes the compiled field.
+func (f *Field) Info() FieldInfo {
+	info := f.f.Info()
+	return FieldInfo{
+		Kind:   FieldKind(info.Kind),
+		Prefix: info.Prefix,
+		Secret: info.Secret,
+		Auto:   info.Auto,
+		Tag:    FieldTag(info.Tag),
+	}
+}
+
+// WithoutFileKey turns off the KEY_FILE companion of f and returns f. Generated
+// loaders call it for fields whose companion key another field of the same struct
+// declares, as config.Load does.
+func (f *Field) WithoutFileKey() *Field {
+	f.f.WithoutFileKey()
+	return f
+}
+
+// Element returns the field that checks the slice elements or map values of f.
+func (f *Field) Element() *Field {
+	f.deriveFields()
+	return f.element
+}
+
+// MapKey returns the field that checks the map keys of f.
+func (f *Field) MapKey() *Field {
+	f.deriveFields()
+	return f.key
+}
+
+func (f *Field) deriveFields() {
+	f.derive.Do(func() {
+		f.element = &Field{f: f.f.Element()}
+		f.key = &Field{f: f.f.MapKey()}
+	})
+}
+
+// Split splits a slice value on the field's separator.
+func (f *Field) Split(raw string) ([]string, error) {
+	return f.f.Split(raw)
+}
+
+// SplitMap splits a map value into its entries.
+func (f *Field) SplitMap(raw string) (map[string]string, error) {
+	return f.f.SplitMap(raw)
+}
+
+// ParseInt parses a signed integer of the given size in the field's format, such
+// as format=bytes.
+func (f *Field) ParseInt(raw string, bits int) (int64, error) {
+	return f.f.ParseInt(raw, bits)
+}
+
+// DecodeBinary decodes a base64 or hex value, as given by the field's format.
+func (f *Field) DecodeBinary(raw string) ([]byte, error) {
+	return f.f.DecodeBinary(raw)
+}
+
+// CheckString applies the field's constraints to a string value.
+func (f *Field) CheckString(value string) error {
+	return f.f.CheckString(value)
+}
+
+// CheckCollection applies the field's constraints to a slice or map of the named
+// type with length elements. stringElems reports whether the elements are
+// strings.
+func (f *Field) CheckCollection(length int, stringElems bool, typeName string) error {
+	return f.f.CheckCollection(length, stringElems, typeName)
+}
+
+// CheckInt applies the field's constraints to a signed integer of the named type.
+func (f *Field) CheckInt(value int64, typeName string) error {
+	return f.f.CheckInt(value, typeName)
+}
+
+// CheckUint applies the field's constraints to an unsigned integer of the named
+// type.
+func (f *Field) CheckUint(value uint64, typeName string) error {
+	return f.f.CheckUint(value, typeName)
+}
+
+// CheckFloat applies the field's constraints to a float of the named type.
+func (f *Field) CheckFloat(value float64, typeName string) error {
+	return f.f.CheckFloat(value, typeName)
+}
+
+// CheckDuration applies the field's constraints to a time.Duration.
+func (f *Field) CheckDuration(value time.Duration, typeName string) error {
+	return f.f.CheckDuration(value, typeName)
+}
+
+// CheckOther rejects constraints that do not apply to values of the named type,
+// such as bool, time.Time and url.URL.
+func (f *Field) CheckOther(typeName string) error {
+	return f.f.CheckOther(typeName)
+}
+
+// ParseURL parses an absolute URL with a scheme and host.
+func ParseURL(raw string) (*url.URL, error) {
+	return bridge.ParseURL(raw)
+}
+
+// ElementError names the slice element or map entry whose value failed to decode,
+// such as "element 2" or "key \"eu\"".
+func ElementError(err error, format string, args ...any) error {
+	return bridge.WrapElementError(err, format, args...)
+}
+
+// Session is the state of one call to a generated loader.
+type Session struct {
+	s bridge.Session
+}
+
+// NewSession starts a generated load from source. auto reports whether the loader
+// was generated with -auto; config.WithAutoKeys is rejected by loaders generated
+// without it, since their keys are fixed.
+func NewSession(source config.Lookuper, auto bool, opts ...config.Option) *Session {
+	return &Session{s: bridge.NewSession(source, auto, opts)}
+}
+
+// Root returns the struct passed to the generated loader.
+func (s *Session) Root() *Struct {
+	return &Struct{st: s.s.Root()}
+}
+
+// Err finishes the session and returns its errors as a *config.LoadError, or nil.
+func (s *Session) Err() error {
+	return s.s.Err()
+}
+
+// Struct is a struct being loaded by a generated loader. A nil *Struct, returned
+// for fields whose tags are invalid, ignores every call.
+type Struct struct {
+	st bridge.Struct
+}
+
+func wrapStruct(st bridge.Struct) *Struct {
+	if st == nil {
+		return nil
+	}
+	return &Struct{st: st}
+}
+
+// Nested returns the nested struct field f. optional reports that the field is a
+// nil pointer, which is only set when Done reports that a field was loaded.
+func (st *Struct) Nested(f *Field, optional bool) *Struct {
+	if st == nil {
+		return nil
+	}
+	return wrapStruct(st.st.Nested(f.f, optional))
+}
+
+// Done checks st's conditions and, when target implements config.Validator, calls
+// its Validate method. It reports whether any field of st was set.
+func (st *Struct) Done(target any) bool {
+	if st == nil {
+		return false
+	}
+	return st.st.Done(target)
+}
+
+// Action is what a generated loader does with a value field after Lookup.
+type Action int
+
+const (
+	// Skip leaves the field as it is.
+	Skip Action = bridge.Skip
+	// Decode decodes the raw value returned by Lookup, then calls Decoded.
+	Decode Action = bridge.Decode
+	// Keep calls Keep with the field's current value.
+	Keep Action = bridge.Keep
+)
+
+// Lookup resolves the value field f of st. zero reports whether the field holds
+// the zero value of its type, which merge modes consult.
+func (st *Struct) Lookup(f *Field, zero bool) (string, Action) {
+	if st == nil {
+		return "", Skip
+	}
+	raw, action := st.st.Lookup(f.f, zero)
+	return raw, Action(action)
+}
+
+// Keep records that the field passed to Lookup keeps its current value, formatted
+// with fmt.Sprint, or as is for strings.
+func (st *Struct) Keep(existing string) {
+	st.st.Keep(existing)
+}
+
+// Decoded reports the outcome of decoding and checking the field passed to Lookup,
+// a field of the named type, and whether the decoded value may be assigned.
+func (st *Struct) Decoded(typeName string, err error) bool {
+	return st.st.Decoded(typeName, err)
+}
+
+// LoadValue loads the value field f of st into target with reflection. Generated
+// loaders use it for types they do not parse themselves, such as types that
+// implement encoding.TextUnmarshaler and types with registered parsers.
+func LoadValue[T any](st *Struct, f *Field, target *T) {
+	if st == nil {
+		return
+	}
+	st.st.LoadValue(f.f, reflect.ValueOf(target).Elem())
+}
+
+// Collection is a struct slice or map field being loaded by a generated loader.
+type Collection struct {
+	c bridge.Collection
+}
+
+// Collection discovers the elements of the struct slice or map field f, given the
+// keys of an element relative to its prefix. existing reports that the field is not
+// nil. For slices the ids are the indexes "0" through "n-1"; for maps they are the
+// sorted map keys. The field should be replaced only when ids is not empty.
+func (st *Struct) Collection(f *Field, slice bool, elementKeys []string, existing bool) (*Collection, []string) {
+	if st == nil {
+		return nil, nil
+	}
+	c, ids := st.st.Collection(f.f, slice, elementKeys, existing)
+	if c == nil {
+		return nil, nil
+	}
+	return &Collection{c: c}, ids
+}
+
+// Element returns the element of c identified by id.
+func (c *Collection) Element(id string) *Struct {
+	if c == nil {
+		return nil
+	}
+	return wrapStruct(c.c.Element(id))
+}
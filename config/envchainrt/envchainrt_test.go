@@ -0,0 +1,113 @@
+package envchainrt
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stuft2/envchain/config"
+)
+
+func TestCompileFieldDescribesTags(t *testing.T) {
+	field := CompileField("Port", `env:"PORT,default=8080,alias=LISTEN_PORT,secret"`, false, ShapeValue, false)
+	if err := field.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := FieldInfo{
+		Kind: FieldValue,
+		Tag: FieldTag{
+			Key:        "PORT",
+			Aliases:    []string{"LISTEN_PORT"},
+			Default:    "8080",
+			HasDefault: true,
+			Secret:     true,
+		},
+	}
+	if got := field.Info(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	nested := CompileField("DB", `envPrefix:"DB_"`, false, ShapeStruct, false).Info()
+	if nested.Kind != FieldNested || nested.Prefix != "DB_" {
+		t.Fatalf("unexpected nested field %+v", nested)
+	}
+}
+
+func TestSessionLoadsFieldsAndReportsErrors(t *testing.T) {
+	var target struct {
+		Port int
+		Name string
+	}
+	port := CompileField("Port", `env:"PORT,min=1"`, false, ShapeValue, false)
+	name := CompileField("Name", `env:"NAME,bogus"`, false, ShapeValue, false)
+
+	s := NewSession(config.MapSource{"APP_PORT": "0", "APP_NAME": "api"}, false, config.WithPrefix("APP_"))
+	st := s.Root()
+	LoadValue(st, port, &target.Port)
+	LoadValue(st, name, &target.Name)
+	st.Done(&target)
+
+	var loadErr *config.LoadError
+	if err := s.Err(); !errors.As(err, &loadErr) || len(loadErr.Errors) != 2 {
+		t.Fatalf("expected two field errors, got %v", err)
+	}
+	if loadErr.Errors[0].Key != "APP_PORT" || loadErr.Errors[0].Kind != config.KindValidation {
+		t.Fatalf("unexpected error %+v", loadErr.Errors[0])
+	}
+	if loadErr.Errors[1].Kind != config.KindTag {
+		t.Fatalf("unexpected error %+v", loadErr.Errors[1])
+	}
+}
+
+func TestNilStructIgnoresCalls(t *testing.T) {
+	var st *Struct
+	var value int
+	LoadValue(st, CompileField("Port", `env:"PORT"`, false, ShapeValue, false), &value)
+	if st.Nested(CompileField("DB", `envPrefix:"DB_"`, false, ShapeStruct, false), false) != nil {
+		t.Fatal("expected a nil nested struct")
+	}
+	if st.Done(&value) {
+		t.Fatal("expected no fields to be set")
+	}
+}
+
+func TestLookupDecodedAndKeep(t *testing.T) {
+	target := struct {
+		Port int
+		Name string
+	}{Name: "flag"}
+	port := CompileField("Port", `env:"PORT,min=1"`, false, ShapeValue, false)
+	name := CompileField("Name", `env:"NAME"`, false, ShapeValue, false)
+
+	s := NewSession(config.MapSource{"PORT": "0", "NAME": "api"}, false, config.WithFillZero())
+	st := s.Root()
+	raw, action := st.Lookup(port, target.Port == 0)
+	if raw != "0" || action != Decode {
+		t.Fatalf("expected PORT to be decoded, got %q and %v", raw, action)
+	}
+	if st.Decoded("int", port.CheckInt(0, "int")) {
+		t.Fatal("expected the out-of-range port to be rejected")
+	}
+	if _, action := st.Lookup(name, target.Name == ""); action != Keep {
+		t.Fatalf("expected NAME to keep its value, got %v", action)
+	}
+	st.Keep(target.Name)
+	st.Done(&target)
+
+	var loadErr *config.LoadError
+	if err := s.Err(); !errors.As(err, &loadErr) || len(loadErr.Errors) != 1 || loadErr.Errors[0].Key != "PORT" {
+		t.Fatalf("expected one error for PORT, got %v", err)
+	}
+}
+
+func TestWithoutFileKeyLeavesCompanionToItsField(t *testing.T) {
+	log := CompileField("Log", `env:"LOG"`, false, ShapeValue, false).WithoutFileKey()
+
+	s := NewSession(config.MapSource{"LOG_FILE": "/var/log/app.log"}, false)
+	if _, action := s.Root().Lookup(log, true); action != Skip {
+		t.Fatalf("expected LOG to be unset, got %v", action)
+	}
+	if err := s.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrRequired reports a required variable that is unset and has no default.
+	ErrRequired = errors.New("required variable is unset")
+	// ErrParse reports a value that could not be converted to the field type.
+	ErrParse = errors.New("invalid value")
+	// ErrValidation reports a parsed value that violates a tag constraint.
+	ErrValidation = errors.New("validation failed")
+	// ErrInvalidTag reports a malformed env tag or an unsupported field.
+	ErrInvalidTag = errors.New("invalid env tag")
+	// ErrFile reports a value file that could not be read.
+	ErrFile = errors.New("cannot read value file")
+	// ErrUnknownKey reports a variable under a strict prefix that no field reads.
+	ErrUnknownKey = errors.New("unknown variable")
+)
+
+// ErrorKind classifies a FieldError.
+type ErrorKind string
+
+const (
+	KindMissing    ErrorKind = "missing"
+	KindParse      ErrorKind = "parse"
+	KindValidation ErrorKind = "validation"
+	KindTag        ErrorKind = "tag"
+	KindFile       ErrorKind = "file"
+	KindUnknown    ErrorKind = "unknown"
+)
+
+func (k ErrorKind) sentinel() error {
+	switch k {
+	case KindMissing:
+		return ErrRequired
+	case KindParse:
+		return ErrParse
+	case KindValidation:
+		return ErrValidation
+	case KindTag:
+		return ErrInvalidTag
+	case KindFile:
+		return ErrFile
+	case KindUnknown:
+		return ErrUnknownKey
+	default:
+		return nil
+	}
+}
+
+// FieldError describes a single field that failed to load.
+type FieldError struct {
+	// Field is the dotted Go path of the field, such as "DB.Port". Errors returned
+	// by Validator are attributed to the struct's path, which is empty for the root.
+	// Unknown variables reported in strict mode have no field.
+	Field string
+	// Key is the environment variable consulted for the field, if any.
+	Key string
+	// Value is the raw value that failed to parse or validate. It is empty when
+	// Redacted is set.
+	Value string
+	// Redacted reports whether the raw value was withheld because the field is secret
+	// or the value was read from a file.
+	Redacted bool
+	// File is the path the value was read from, if any.
+	File string
+	// Kind classifies the failure.
+	Kind ErrorKind
+	// Err is the underlying cause.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	switch {
+	case e.Kind == KindMissing && e.Err != nil && e.Err != ErrRequired:
+		return fmt.Sprintf("field %s: environment variable %q is %v", e.Field, e.Key, e.Err)
+	case e.Kind == KindMissing:
+		return fmt.Sprintf("field %s: environment variable %q is required", e.Field, e.Key)
+	case e.Field == "":
+		return e.Err.Error()
+	case e.File != "":
+		return fmt.Sprintf("field %s: env %q file %q: %v", e.Field, e.Key, e.File, e.Err)
+	case e.Key != "" && e.Redacted:
+		return fmt.Sprintf("field %s: env %q value %s: %v", e.Field, e.Key, redactedValue, e.Err)
+	case e.Key != "":
+		return fmt.Sprintf("field %s: env %q value %q: %v", e.Field, e.Key, e.Value, e.Err)
+	default:
+		return fmt.Sprintf("field %s: %v", e.Field, e.Err)
+	}
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel error for the field's kind.
+func (e *FieldError) Is(target error) bool {
+	return target != nil && target == e.Kind.sentinel()
+}
+
+// LoadError aggregates every FieldError produced by a single Load call.
+type LoadError struct {
+	Errors []*FieldError
+}
+
+func (e *LoadError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (e *LoadError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+const redactedValue = "[REDACTED]"
+
+// redactedError hides the message of err, which may quote the raw value, while
+// keeping it reachable through errors.Is.
+type redactedError struct {
+	err      error
+	typeName string
+}
+
+func (e *redactedError) Error() string {
+	// Messages produced by kindError never quote the raw value.
+	var kindErr *kindError
+	if errors.As(e.err, &kindErr) {
+		return kindErr.msg
+	}
+	return fmt.Sprintf("cannot parse value as %s", e.typeName)
+}
+
+func (e *redactedError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+// Warning is a non-fatal diagnostic produced by Load.
+type Warning struct {
+	// Field is the dotted Go path of the field the warning concerns. It is empty for
+	// warnings about the source as a whole, such as unknown variables.
+	Field string
+	// Key is the environment variable the warning concerns.
+	Key     string
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Field == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("field %s: %s", w.Field, w.Message)
+}
+
+// kindError tags an error message with the sentinel assignField uses to classify it.
+type kindError struct {
+	msg      string
+	sentinel error
+}
+
+func validationErrorf(format string, args ...any) error {
+	return &kindError{msg: fmt.Sprintf(format, args...), sentinel: ErrValidation}
+}
+
+func requiredErrorf(format string, args ...any) error {
+	return &kindError{msg: fmt.Sprintf(format, args...), sentinel: ErrRequired}
+}
+
+func parseErrorf(format string, args ...any) error {
+	return &kindError{msg: fmt.Sprintf(format, args...), sentinel: ErrParse}
+}
+
+func tagErrorf(format string, args ...any) error {
+	return &kindError{msg: fmt.Sprintf(format, args...), sentinel: ErrInvalidTag}
+}
+
+func (e *kindError) Error() string {
+	return e.msg
+}
+
+func (e *kindError) Unwrap() error {
+	return e.sentinel
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestLoadReturnsStructuredErrors(t *testing.T) {
+	type dbConfig struct {
+		Password string `env:"DB_PASSWORD,required"`
+	}
+	type testConfig struct {
+		Port  int    `env:"PORT"`
+		Mode  string `env:"MODE,oneof=dev|prod"`
+		Bad   int    `env:"BAD,unknown"`
+		DB    dbConfig
+		Valid string `env:"VALID,default=ok"`
+	}
+
+	source := MapSource{
+		"PORT": "not-a-number",
+		"MODE": "staging",
+	}
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, source)
+	if err == nil {
+		t.Fatal("expected load error")
+	}
+
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected *LoadError, got %T", err)
+	}
+
+	want := []struct {
+		field string
+		key   string
+		value string
+		kind  ErrorKind
+	}{
+		{field: "Port", key: "PORT", value: "not-a-number", kind: KindParse},
+		{field: "Mode", key: "MODE", value: "staging", kind: KindValidation},
+		{field: "Bad", kind: KindTag},
+		{field: "DB.Password", key: "DB_PASSWORD", kind: KindMissing},
+	}
+	if len(loadErr.Errors) != len(want) {
+		t.Fatalf("expected %d field errors, got %d: %v", len(want), len(loadErr.Errors), err)
+	}
+	for i, w := range want {
+		got := loadErr.Errors[i]
+		if got.Field != w.field || got.Key != w.key || got.Value != w.value || got.Kind != w.kind {
+			t.Fatalf("error %d: expected %+v, got %+v", i, w, *got)
+		}
+	}
+
+	if !errors.Is(err, ErrRequired) || !errors.Is(err, ErrParse) || !errors.Is(err, ErrValidation) || !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("expected every sentinel to match, got %v", err)
+	}
+	if errors.Is(loadErr.Errors[0], ErrRequired) {
+		t.Fatal("parse error must not match ErrRequired")
+	}
+
+	var numErr *strconv.NumError
+	if !errors.As(loadErr.Errors[0], &numErr) {
+		t.Fatalf("expected underlying strconv error to be reachable, got %v", loadErr.Errors[0].Err)
+	}
+}
+
+func TestLoadRedactsSecretValues(t *testing.T) {
+	type credentials struct {
+		Token string `env:"TOKEN,oneof=a|b"`
+		Pin   int    `env:"PIN"`
+	}
+	type testConfig struct {
+		Password int               `env:"DB_PASSWORD,secret"`
+		Keys     map[string]string `env:"KEYS,sensitive"`
+		Creds    credentials       `env:",secret"`
+		Port     int               `env:"PORT"`
+	}
+
+	source := MapSource{
+		"DB_PASSWORD": "hunter2",
+		"KEYS":        "s3cr3t-entry",
+		"TOKEN":       "zzz-token",
+		"PIN":         "pin-1234",
+		"PORT":        "public-port",
+	}
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, source)
+	if err == nil {
+		t.Fatal("expected load error")
+	}
+
+	got := err.Error()
+	for _, secret := range []string{"hunter2", "s3cr3t-entry", "zzz-token", "pin-1234"} {
+		if strings.Contains(got, secret) {
+			t.Fatalf("expected %q to be redacted, got %q", secret, got)
+		}
+	}
+	for _, want := range []string{
+		`field Password: env "DB_PASSWORD" value [REDACTED]: cannot parse value as int`,
+		`field Creds.Token: env "TOKEN" value [REDACTED]: value is not in enum [a b]`,
+		`field Port: env "PORT" value "public-port"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected error to contain %q, got %q", want, got)
+		}
+	}
+
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected *LoadError, got %T", err)
+	}
+	for _, fieldErr := range loadErr.Errors {
+		if fieldErr.Field != "Port" && (!fieldErr.Redacted || fieldErr.Value != "") {
+			t.Fatalf("expected %s to be redacted, got %+v", fieldErr.Field, *fieldErr)
+		}
+	}
+	if !errors.Is(err, ErrParse) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected redacted errors to keep their kind, got %v", err)
+	}
+}
+
+func TestLoadRedactsAllValuesWhenRequested(t *testing.T) {
+	type testConfig struct {
+		Labels map[string]string `env:"LABELS"`
+	}
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, MapSource{"LABELS": "team=core,broken-entry"}, WithRedactedValues())
+	if err == nil {
+		t.Fatal("expected load error")
+	}
+	if got := err.Error(); strings.Contains(got, "broken-entry") || !strings.Contains(got, "[REDACTED]") {
+		t.Fatalf("expected value to be redacted, got %q", got)
+	}
+}
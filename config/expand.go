@@ -0,0 +1,111 @@
+package config
+
+import "strings"
+
+// expander substitutes ${VAR} and ${VAR:-fallback} references using the loader's
+// source. Referenced values are expanded recursively; $$ produces a literal $.
+type expander struct {
+	lookup func(key string) (string, bool)
+	stack  []string
+}
+
+func (l *loader) expandValue(raw string) (string, error) {
+	e := &expander{lookup: l.lookup}
+	return e.expand(raw)
+}
+
+func (e *expander) expand(raw string) (string, error) {
+	if !strings.Contains(raw, "$") {
+		return raw, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '$' || i+1 >= len(raw) {
+			b.WriteByte(raw[i])
+			continue
+		}
+
+		switch raw[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '{':
+			end := closingBrace(raw, i+2)
+			if end < 0 {
+				return "", parseErrorf("unterminated variable reference at offset %d", i)
+			}
+			value, err := e.resolve(raw[i+2 : end])
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			i = end
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String(), nil
+}
+
+// resolve expands the body of a single ${...} reference.
+func (e *expander) resolve(expr string) (string, error) {
+	name, fallback, hasFallback := strings.Cut(expr, ":-")
+	if !isVariableName(name) {
+		return "", parseErrorf("invalid variable reference ${%s}", expr)
+	}
+
+	for i, seen := range e.stack {
+		if seen == name {
+			cycle := append(append([]string{}, e.stack[i:]...), name)
+			return "", parseErrorf("variable reference cycle %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	value, ok := e.lookup(name)
+	if !ok || (value == "" && hasFallback) {
+		if !hasFallback {
+			return "", parseErrorf("undefined variable %q", name)
+		}
+		return e.expand(fallback)
+	}
+
+	e.stack = append(e.stack, name)
+	defer func() { e.stack = e.stack[:len(e.stack)-1] }()
+	return e.expand(value)
+}
+
+// closingBrace returns the index of the } matching an opening ${ whose body starts
+// at start, accounting for nested references in fallbacks.
+func closingBrace(raw string, start int) int {
+	depth := 1
+	for i := start; i < len(raw); i++ {
+		switch {
+		case raw[i] == '$' && i+1 < len(raw) && raw[i+1] == '{':
+			depth++
+			i++
+		case raw[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func isVariableName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		ch := name[i]
+		switch {
+		case ch == '_', ch >= 'A' && ch <= 'Z', ch >= 'a' && ch <= 'z':
+		case ch >= '0' && ch <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
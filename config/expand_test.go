@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLoadExpandsVariableReferences(t *testing.T) {
+	type testConfig struct {
+		DatabaseURL string `env:"DATABASE_URL,expand"`
+		CacheDir    string `env:"CACHE_DIR,expand,default=${HOME}/.cache/app"`
+		Region      string `env:"REGION,expand,default=${AWS_REGION:-us-east-1}"`
+		Literal     string `env:"LITERAL,expand"`
+		Unexpanded  string `env:"UNEXPANDED"`
+	}
+
+	source := MapSource{
+		"DATABASE_URL": "postgres://${DB_USER}@${DB_HOST}/app",
+		"DB_USER":      "svc",
+		"DB_HOST":      "${DB_HOST_NAME}:5432",
+		"DB_HOST_NAME": "db.internal",
+		"HOME":         "/home/app",
+		"LITERAL":      "cost: $$5 and $HOME",
+		"UNEXPANDED":   "${HOME}",
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.DatabaseURL != "postgres://svc@db.internal:5432/app" {
+		t.Fatalf("expected expanded database url, got %q", cfg.DatabaseURL)
+	}
+	if cfg.CacheDir != "/home/app/.cache/app" {
+		t.Fatalf("expected expanded default, got %q", cfg.CacheDir)
+	}
+	if cfg.Region != "us-east-1" {
+		t.Fatalf("expected fallback region, got %q", cfg.Region)
+	}
+	if cfg.Literal != "cost: $5 and $HOME" {
+		t.Fatalf("expected literal dollar signs, got %q", cfg.Literal)
+	}
+	if cfg.Unexpanded != "${HOME}" {
+		t.Fatalf("expected fields without expand to stay raw, got %q", cfg.Unexpanded)
+	}
+}
+
+func TestLoadReportsExpansionErrors(t *testing.T) {
+	type testConfig struct {
+		URL      string `env:"URL"`
+		Loop     string `env:"LOOP"`
+		Broken   string `env:"BROKEN"`
+		Password string `env:"PASSWORD,secret"`
+	}
+
+	source := MapSource{
+		"URL":      "https://${API_HOST}/v1",
+		"LOOP":     "${A}",
+		"A":        "x${B}",
+		"B":        "${A}",
+		"BROKEN":   "${OPEN",
+		"PASSWORD": "hunter2${MISSING}",
+	}
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, source, WithExpand())
+	if err == nil {
+		t.Fatal("expected expansion errors")
+	}
+
+	got := err.Error()
+	for _, want := range []string{
+		`field URL: env "URL" value "https://${API_HOST}/v1": undefined variable "API_HOST"`,
+		`field Loop: env "LOOP" value "${A}": variable reference cycle A -> B -> A`,
+		`field Broken: env "BROKEN" value "${OPEN": unterminated variable reference at offset 0`,
+		`field Password: env "PASSWORD" value [REDACTED]: undefined variable "MISSING"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected error to contain %q, got %q", want, got)
+		}
+	}
+	if strings.Contains(got, "hunter2") {
+		t.Fatalf("expected secret value to be redacted, got %q", got)
+	}
+	if !errors.Is(err, ErrParse) {
+		t.Fatalf("expected parse errors, got %v", err)
+	}
+}
+
+func TestLoadExpansionKeepsReferencedSecretsOutOfErrors(t *testing.T) {
+	type testConfig struct {
+		Password string `env:"DB_PASSWORD,secret"`
+		Port     int    `env:"PORT,expand"`
+	}
+
+	source := MapSource{
+		"DB_PASSWORD": "hunter2",
+		"PORT":        "${DB_PASSWORD}",
+	}
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, source)
+	if err == nil {
+		t.Fatal("expected a parse error for PORT")
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("expanded secret leaked: %v", err)
+	}
+	if !strings.Contains(err.Error(), `value "${DB_PASSWORD}"`) {
+		t.Fatalf("expected the unexpanded value in the error, got %v", err)
+	}
+}
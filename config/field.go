@@ -0,0 +1,153 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/stuft2/envchain/config/internal/bridge"
+)
+
+// fieldKind classifies how Load treats a struct field. Its values match
+// envchainrt.FieldKind.
+type fieldKind int
+
+const (
+	// fieldIgnored fields are not loaded.
+	fieldIgnored fieldKind = iota
+	// fieldNested fields are structs, or pointers to structs, whose fields are loaded.
+	fieldNested
+	// fieldCollection fields are slices or string-keyed maps of structs loaded from
+	// indexed or keyed prefixes.
+	fieldCollection
+	// fieldValue fields are loaded from a single variable.
+	fieldValue
+)
+
+// fieldShape describes a field's type as far as tag interpretation is concerned.
+// Its values match envchainrt.FieldShape.
+type fieldShape int
+
+const (
+	// shapeValue is any type that is not a nested struct or struct collection.
+	shapeValue fieldShape = iota
+	// shapeStruct is a struct, or pointer to a struct, that Load recurses into:
+	// not time.Time, url.URL, Optional or a type that decodes itself from text.
+	shapeStruct
+	// shapeCollection is a slice, or a map with string keys, of shapeStruct elements.
+	shapeCollection
+)
+
+// compiledField is a struct field whose tags have been compiled for envchainrt,
+// which tools use to inspect tags and generated loaders use to load fields.
+type compiledField struct {
+	plan fieldPlan
+	// compile sets plan.set once the field's type is known from its first load.
+	compile sync.Once
+	// element and key hold the options of slice elements and map entries, and of
+	// map keys, once first asked for.
+	derive       sync.Once
+	element, key *compiledField
+}
+
+// compileFieldHook interprets the tags of an exported struct field named name,
+// whose type has the given shape, exactly as Load does.
+func compileFieldHook(name string, tag reflect.StructTag, anonymous bool, shape int, auto bool) bridge.Field {
+	return &compiledField{plan: compileTags(name, tag, anonymous, fieldShape(shape), auto)}
+}
+
+func (f *compiledField) Err() error {
+	return f.plan.err
+}
+
+func (f *compiledField) Info() bridge.FieldInfo {
+	plan := f.plan
+	return bridge.FieldInfo{
+		Kind:   int(plan.kind),
+		Prefix: plan.prefix,
+		Secret: plan.secret,
+		Auto:   plan.auto,
+		Tag: bridge.FieldTag{
+			Key:        plan.opts.key,
+			Aliases:    append([]string(nil), plan.opts.aliases...),
+			Required:   plan.opts.required,
+			Default:    plan.opts.defaultVal,
+			HasDefault: plan.opts.hasDefault,
+			OneOf:      append([]string(nil), plan.opts.oneOf...),
+			Layout:     plan.opts.layout,
+			Format:     plan.opts.format,
+			Secret:     plan.opts.secret,
+			File:       plan.opts.file,
+		},
+	}
+}
+
+// WithoutFileKey turns off the KEY_FILE companion, as claimFileKeys does for
+// fields loaded by Load.
+func (f *compiledField) WithoutFileKey() {
+	f.plan.opts.noFileKey = true
+}
+
+func (f *compiledField) Element() bridge.Field {
+	f.deriveFields()
+	return f.element
+}
+
+func (f *compiledField) MapKey() bridge.Field {
+	f.deriveFields()
+	return f.key
+}
+
+func (f *compiledField) deriveFields() {
+	f.derive.Do(func() {
+		f.element = &compiledField{plan: fieldPlan{kind: fieldValue, opts: f.plan.opts.elementOptions()}}
+		f.key = &compiledField{plan: fieldPlan{kind: fieldValue, opts: f.plan.opts.keyOptions()}}
+	})
+}
+
+// The methods below decode and check values the way the setters compiled by
+// compileSetter do, for loaders that parse built-in types themselves.
+
+func (f *compiledField) Split(raw string) ([]string, error) {
+	return parseStringSlice(raw, f.plan.opts.sep)
+}
+
+func (f *compiledField) SplitMap(raw string) (map[string]string, error) {
+	return parseMap(raw, f.plan.opts.kvSep, f.plan.opts.entrySep)
+}
+
+func (f *compiledField) ParseInt(raw string, bits int) (int64, error) {
+	return parseSignedInteger(raw, bits, f.plan.opts.format)
+}
+
+func (f *compiledField) DecodeBinary(raw string) ([]byte, error) {
+	return decodeBinary(raw, f.plan.opts.format)
+}
+
+func (f *compiledField) CheckString(value string) error {
+	return validateString(value, f.plan.opts)
+}
+
+func (f *compiledField) CheckCollection(length int, stringElems bool, typeName string) error {
+	return validateCollection(length, stringElems, typeName, f.plan.opts)
+}
+
+func (f *compiledField) CheckInt(value int64, typeName string) error {
+	return validateInt(value, typeName, f.plan.opts)
+}
+
+func (f *compiledField) CheckUint(value uint64, typeName string) error {
+	return validateUint(value, typeName, f.plan.opts)
+}
+
+func (f *compiledField) CheckFloat(value float64, typeName string) error {
+	return validateFloat(value, typeName, f.plan.opts)
+}
+
+func (f *compiledField) CheckDuration(value time.Duration, typeName string) error {
+	return validateDuration(value, typeName, f.plan.opts)
+}
+
+func (f *compiledField) CheckOther(typeName string) error {
+	return validateScalar(typeName, f.plan.opts, false)
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// fileKeySuffix names the companion variable holding a path to the value,
+	// following the Docker and Kubernetes secrets convention.
+	fileKeySuffix = "_FILE"
+
+	defaultMaxFileSize = 1 << 20
+)
+
+// readValueFile reads a value from path, trimming trailing newlines. Errors name
+// the file but never include its contents.
+func readValueFile(path string, maxSize int64) (string, error) {
+	if path == "" {
+		return "", errors.New("file path is empty")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return "", err
+	}
+	if int64(len(data)) > maxSize {
+		return "", fmt.Errorf("file exceeds %d bytes", maxSize)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// claimFileKeys turns off the KEY_FILE companion of value fields whose companion
+// key another field of the same struct declares, such as LOG_FILE beside LOG, so
+// that variable is only read by the field that declares it.
+func claimFileKeys(fields []fieldPlan) {
+	declared := map[string]bool{}
+	for _, field := range fields {
+		if field.kind != fieldValue {
+			continue
+		}
+		declared[field.opts.key] = true
+		for _, alias := range field.opts.aliases {
+			declared[alias] = true
+		}
+	}
+	for i, field := range fields {
+		if field.kind == fieldValue && declared[field.opts.key+fileKeySuffix] {
+			fields[i].opts.noFileKey = true
+		}
+	}
+}
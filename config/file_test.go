@@ -0,0 +1,109 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeValueFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	return path
+}
+
+func TestLoadReadsValuesFromFiles(t *testing.T) {
+	type testConfig struct {
+		Password string `env:"DB_PASSWORD,required,secret"`
+		Port     int    `env:"DB_PORT"`
+		Token    string `env:"TOKEN_PATH,file"`
+	}
+
+	source := MapSource{
+		"DB_PASSWORD_FILE": writeValueFile(t, "password", "hunter2\n"),
+		"DB_PORT":          "5432",
+		"DB_PORT_FILE":     writeValueFile(t, "port", "ignored\n"),
+		"TOKEN_PATH":       writeValueFile(t, "token", "abc123\r\n"),
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Password != "hunter2" {
+		t.Fatalf("expected password from file, got %q", cfg.Password)
+	}
+	if cfg.Port != 5432 {
+		t.Fatalf("expected variable to win over file, got %d", cfg.Port)
+	}
+	if cfg.Token != "abc123" {
+		t.Fatalf("expected token from file option, got %q", cfg.Token)
+	}
+}
+
+func TestLoadSkipsFileCompanionDeclaredByAnotherField(t *testing.T) {
+	type testConfig struct {
+		Log     string `env:"LOG,default=info"`
+		LogFile string `env:"LOG_FILE"`
+	}
+
+	source := MapSource{"LOG_FILE": writeValueFile(t, "app.log", "2026-01-01 some log line\n")}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source, WithStrict("")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Log != "info" {
+		t.Fatalf("expected the default rather than the file contents, got %q", cfg.Log)
+	}
+	if cfg.LogFile != source["LOG_FILE"] {
+		t.Fatalf("expected LOG_FILE to be read as a plain value, got %q", cfg.LogFile)
+	}
+}
+
+func TestLoadReportsFileErrorsWithoutContents(t *testing.T) {
+	type testConfig struct {
+		Port    int    `env:"PORT"`
+		Missing string `env:"MISSING"`
+		Large   string `env:"LARGE,file"`
+	}
+
+	portFile := writeValueFile(t, "port", "s3cr3t-port\n")
+	missingFile := filepath.Join(t.TempDir(), "missing")
+	largeFile := writeValueFile(t, "large", strings.Repeat("x", 64))
+
+	source := MapSource{
+		"PORT_FILE":    portFile,
+		"MISSING_FILE": missingFile,
+		"LARGE":        largeFile,
+	}
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, source, WithMaxFileSize(32))
+	if err == nil {
+		t.Fatal("expected file errors")
+	}
+
+	got := err.Error()
+	if strings.Contains(got, "s3cr3t-port") || strings.Contains(got, "xxxx") {
+		t.Fatalf("expected file contents to stay out of errors, got %q", got)
+	}
+	for _, want := range []string{
+		`field Port: env "PORT_FILE" file "` + portFile + `": cannot parse value as int`,
+		`field Missing: env "MISSING_FILE" file "` + missingFile + `": open `,
+		`field Large: env "LARGE" file "` + largeFile + `": file exceeds 32 bytes`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected error to contain %q, got %q", want, got)
+		}
+	}
+	if !errors.Is(err, ErrFile) || !errors.Is(err, ErrParse) {
+		t.Fatalf("expected file and parse sentinels, got %v", err)
+	}
+}
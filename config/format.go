@@ -0,0 +1,46 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"reflect"
+	"strings"
+)
+
+const (
+	formatBytes  = "bytes"
+	formatJSON   = "json"
+	formatBase64 = "base64"
+	formatHex    = "hex"
+)
+
+// isEncodingFormat reports whether format decodes the raw value as a whole before
+// it is assigned, rather than changing how a scalar is parsed.
+func isEncodingFormat(format string) bool {
+	switch format {
+	case formatJSON, formatBase64, formatHex:
+		return true
+	default:
+		return false
+	}
+}
+
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// decodeBinary decodes standard base64, with or without padding, or hex.
+func decodeBinary(raw, format string) ([]byte, error) {
+	if format == formatHex {
+		decoded, err := hex.DecodeString(raw)
+		if err != nil {
+			return nil, parseErrorf("invalid hex value")
+		}
+		return decoded, nil
+	}
+	decoded, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(raw, "="))
+	if err != nil {
+		return nil, parseErrorf("invalid base64 value")
+	}
+	return decoded, nil
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadDecodesJSONValues(t *testing.T) {
+	type endpoint struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	type testConfig struct {
+		Primary   endpoint            `env:"PRIMARY,format=json"`
+		Upstreams []endpoint          `env:"UPSTREAMS,format=json"`
+		Limits    map[string]int      `env:"LIMITS,format=json,maxlen=2"`
+		Fallback  *endpoint           `env:"FALLBACK,format=json"`
+		Retry     Optional[[]int]     `env:"RETRY,format=json"`
+		Unset     *endpoint           `env:"UNSET,format=json"`
+		Labels    map[string][]string `env:"LABELS,format=json,default={\"team\":[\"core\"]}"`
+	}
+
+	source := MapSource{
+		"PRIMARY":   `{"host":"primary.internal","port":5432}`,
+		"UPSTREAMS": `[{"host":"a"},{"host":"b","port":81}]`,
+		"LIMITS":    `{"read":10,"write":5}`,
+		"FALLBACK":  `{"host":"fallback"}`,
+		"RETRY":     `[1,2,4]`,
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Primary != (endpoint{Host: "primary.internal", Port: 5432}) {
+		t.Fatalf("unexpected primary: %+v", cfg.Primary)
+	}
+	if !reflect.DeepEqual(cfg.Upstreams, []endpoint{{Host: "a"}, {Host: "b", Port: 81}}) {
+		t.Fatalf("unexpected upstreams: %+v", cfg.Upstreams)
+	}
+	if !reflect.DeepEqual(cfg.Limits, map[string]int{"read": 10, "write": 5}) {
+		t.Fatalf("unexpected limits: %+v", cfg.Limits)
+	}
+	if cfg.Fallback == nil || cfg.Fallback.Host != "fallback" {
+		t.Fatalf("unexpected fallback: %+v", cfg.Fallback)
+	}
+	if retry, ok := cfg.Retry.Get(); !ok || !reflect.DeepEqual(retry, []int{1, 2, 4}) {
+		t.Fatalf("unexpected retry: %+v", cfg.Retry)
+	}
+	if cfg.Unset != nil {
+		t.Fatalf("expected unset pointer to stay nil, got %+v", cfg.Unset)
+	}
+	if !reflect.DeepEqual(cfg.Labels, map[string][]string{"team": {"core"}}) {
+		t.Fatalf("unexpected labels: %+v", cfg.Labels)
+	}
+}
+
+func TestLoadDecodesBase64AndHexValues(t *testing.T) {
+	type testConfig struct {
+		Key      []byte            `env:"KEY,format=base64,len=4"`
+		Unpadded []byte            `env:"UNPADDED,format=base64"`
+		Salt     []byte            `env:"SALT,format=hex"`
+		Token    string            `env:"TOKEN,format=base64"`
+		Keys     [][]byte          `env:"KEYS,format=hex"`
+		Headers  map[string]string `env:"HEADERS,format=base64"`
+		Secret   *string           `env:"SECRET,format=hex"`
+	}
+
+	source := MapSource{
+		"KEY":      "3q2+7w==",
+		"UNPADDED": "3q2+7w",
+		"SALT":     "deadbeef",
+		"TOKEN":    "aGVsbG8=",
+		"KEYS":     "00ff,abcd",
+		"HEADERS":  "X-Api=a2V5",
+		"SECRET":   "736563726574",
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []byte{0xde, 0xad, 0xbe, 0xef}
+	if !bytes.Equal(cfg.Key, want) || !bytes.Equal(cfg.Unpadded, want) || !bytes.Equal(cfg.Salt, want) {
+		t.Fatalf("unexpected bytes: %x %x %x", cfg.Key, cfg.Unpadded, cfg.Salt)
+	}
+	if cfg.Token != "hello" {
+		t.Fatalf("expected decoded token, got %q", cfg.Token)
+	}
+	if !reflect.DeepEqual(cfg.Keys, [][]byte{{0x00, 0xff}, {0xab, 0xcd}}) {
+		t.Fatalf("unexpected keys: %x", cfg.Keys)
+	}
+	if !reflect.DeepEqual(cfg.Headers, map[string]string{"X-Api": "key"}) {
+		t.Fatalf("expected map values, not keys, to be decoded, got %v", cfg.Headers)
+	}
+	if cfg.Secret == nil || *cfg.Secret != "secret" {
+		t.Fatalf("unexpected secret: %v", cfg.Secret)
+	}
+}
+
+func TestLoadReportsFormatErrors(t *testing.T) {
+	type testConfig struct {
+		Endpoint struct {
+			Host string `json:"host"`
+		} `env:"ENDPOINT,format=json,secret"`
+		Key  []byte `env:"KEY,format=base64"`
+		Salt []byte `env:"SALT,format=hex"`
+		Port int    `env:"PORT,format=hex"`
+	}
+
+	source := MapSource{
+		"ENDPOINT": `{"host":`,
+		"KEY":      "not base64!",
+		"SALT":     "xyz",
+		"PORT":     "1f",
+	}
+
+	err := LoadFrom(&testConfig{}, source)
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected *LoadError, got %T (%v)", err, err)
+	}
+	if len(loadErr.Errors) != 4 {
+		t.Fatalf("expected 4 errors, got %v", err)
+	}
+
+	wantKinds := []ErrorKind{KindParse, KindParse, KindParse, KindTag}
+	for i, fieldErr := range loadErr.Errors {
+		if fieldErr.Kind != wantKinds[i] {
+			t.Fatalf("expected %s error for %s, got %s (%v)", wantKinds[i], fieldErr.Field, fieldErr.Kind, fieldErr)
+		}
+	}
+
+	got := err.Error()
+	if strings.Contains(got, `{"host":`) {
+		t.Fatalf("expected secret JSON value to be redacted, got %q", got)
+	}
+	for _, want := range []string{"invalid base64 value", "invalid hex value", "format=hex requires a string or []byte field, got int"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %q in %q", want, got)
+		}
+	}
+}
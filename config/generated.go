@@ -0,0 +1,225 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+
+	"github.com/stuft2/envchain/config/internal/bridge"
+)
+
+// The declarations in this file implement the loaders generated by envchain-gen,
+// which reach them through package envchainrt in place of walking the struct with
+// reflection. They share every rule with Load.
+
+func init() {
+	bridge.NewSession = newSession
+	bridge.CompileField = compileFieldHook
+	bridge.ParseURL = parseURL
+	bridge.WrapElementError = wrapElementError
+}
+
+// session is the state of one call to a generated loader.
+type session struct {
+	l    *loader
+	auto bool
+	errs []*FieldError
+	// pending is the value field between Lookup and Keep or Decoded. Generated
+	// loaders finish each field before looking up the next, so the session holds
+	// one for all its structs.
+	pending pendingField
+}
+
+// newSession starts a generated load from source. auto reports whether the loader
+// was generated with -auto; WithAutoKeys is rejected by loaders generated without
+// it, since their keys are fixed.
+func newSession(source bridge.Lookuper, auto bool, opts any) bridge.Session {
+	s := &session{l: newLoader(append(opts.([]Option), WithSource(source))), auto: auto}
+	if s.l.auto && !auto {
+		s.errs = append(s.errs, &FieldError{Kind: KindTag, Err: errors.New("WithAutoKeys requires a loader generated with -auto")})
+	}
+	return s
+}
+
+func (s *session) Root() bridge.Struct {
+	return &structState{s: s, sc: scope{prefix: s.l.prefix, auto: s.auto}}
+}
+
+// Err finishes the session and returns its errors as a *LoadError, or nil.
+func (s *session) Err() error {
+	s.errs = append(s.errs, s.l.checkUnknownKeys()...)
+	if len(s.errs) == 0 {
+		return nil
+	}
+	return &LoadError{Errors: s.errs}
+}
+
+// structState is a struct being loaded by a generated loader.
+type structState struct {
+	s       *session
+	parent  *structState
+	sc      scope
+	conds   conditions
+	changed bool
+}
+
+type pendingField struct {
+	spec  fieldSpec
+	value resolvedValue
+}
+
+// resolve places f in st, recording a tag error if its tags are invalid.
+func (st *structState) resolve(f bridge.Field) (fieldSpec, bool) {
+	spec, err := f.(*compiledField).plan.resolve(st.sc)
+	if err != nil {
+		st.s.errs = append(st.s.errs, &FieldError{Field: spec.scope.path, Kind: KindTag, Err: err})
+		return spec, false
+	}
+	return spec, true
+}
+
+// Nested returns the nested struct field f. optional reports that the field is a
+// nil pointer, which is only set when Done reports that a field was loaded.
+func (st *structState) Nested(f bridge.Field, optional bool) bridge.Struct {
+	spec, ok := st.resolve(f)
+	if !ok || spec.kind != fieldNested {
+		return nil
+	}
+	spec.scope.optional = spec.scope.optional || optional
+	return &structState{s: st.s, parent: st, sc: spec.scope}
+}
+
+// Done checks st's conditions and, when target implements Validator, calls its
+// Validate method. It reports whether any field of st was set.
+func (st *structState) Done(target any) bool {
+	st.s.errs = append(st.s.errs, st.s.l.finishStruct(st.sc, st.conds, st.changed, func() error {
+		if validator, ok := target.(Validator); ok {
+			return validator.Validate()
+		}
+		return nil
+	})...)
+	if st.changed && st.parent != nil {
+		st.parent.changed = true
+	}
+	return st.changed
+}
+
+// LoadValue loads the value field f of st into target.
+func (st *structState) LoadValue(f bridge.Field, target reflect.Value) {
+	field := f.(*compiledField)
+	field.compile.Do(func() {
+		if field.plan.kind == fieldValue {
+			field.plan.set = compileSetter(target.Type(), field.plan.opts)
+		}
+	})
+	spec, ok := st.resolve(field)
+	if !ok || spec.kind != fieldValue {
+		return
+	}
+	changed, fieldErr := st.s.l.loadValueField(target, spec, &st.conds)
+	if fieldErr != nil {
+		st.s.errs = append(st.s.errs, fieldErr)
+		return
+	}
+	st.changed = st.changed || changed
+}
+
+// Lookup resolves the value field f of st, whose current value is the zero value of
+// its type when zero is true. The generated loader then decodes the returned raw
+// value and reports the outcome with Decoded, or calls Keep with the current value.
+func (st *structState) Lookup(f bridge.Field, zero bool) (string, int) {
+	spec, ok := st.resolve(f)
+	if !ok || spec.kind != fieldValue {
+		return "", bridge.Skip
+	}
+	value, action, fieldErr := st.s.l.resolveField(spec.scope.path, spec.opts, zero)
+	st.s.pending = pendingField{spec: spec, value: value}
+	switch action {
+	case fieldKeep:
+		return "", bridge.Keep
+	case fieldDecode:
+		return value.raw, bridge.Decode
+	}
+	st.finishValue(fieldErr)
+	return "", bridge.Skip
+}
+
+// Keep records that the pending field keeps its current value, formatted as
+// existingValue does.
+func (st *structState) Keep(existing string) {
+	p := st.s.pending
+	st.s.pending.value = st.s.l.keepField(p.spec.scope.path, p.spec.opts, resolvedValue{raw: existing, source: SourceExisting})
+	st.finishValue(nil)
+}
+
+// Decoded reports whether the pending field may be assigned the value the
+// generated loader decoded, given the error from decoding and checking it. err
+// is reported as for a field of the named type.
+func (st *structState) Decoded(typeName string, err error) bool {
+	p := st.s.pending
+	var fieldErr *FieldError
+	if err != nil {
+		fieldErr = st.s.l.decodeError(p.spec.scope.path, p.spec.opts, p.value, typeName, err)
+	}
+	st.finishValue(fieldErr)
+	return err == nil
+}
+
+// finishValue records the pending field for the struct's conditions, as
+// loadValueField does.
+func (st *structState) finishValue(fieldErr *FieldError) {
+	p := st.s.pending
+	st.s.pending = pendingField{}
+	st.conds.add(p.spec.scope.path, p.spec.opts, p.value)
+	if fieldErr != nil {
+		st.s.errs = append(st.s.errs, fieldErr)
+		return
+	}
+	st.changed = st.changed || p.value.set()
+}
+
+// collectionState is a struct slice or map field being loaded by a generated
+// loader.
+type collectionState struct {
+	s  *session
+	sc scope
+}
+
+// Collection discovers the elements of the struct slice or map field f, given the
+// keys of an element relative to its prefix. existing reports that the field is not
+// nil. For slices the ids are the indexes "0" through "n-1"; for maps they are the
+// sorted map keys. The field should be replaced only when ids is not empty.
+func (st *structState) Collection(f bridge.Field, slice bool, elementKeys []string, existing bool) (bridge.Collection, []string) {
+	spec, ok := st.resolve(f)
+	if !ok || spec.kind != fieldCollection {
+		return nil, nil
+	}
+	if st.s.l.keepsCollection(existing) {
+		st.s.l.skipCollection(spec.scope, elementKeys)
+		return nil, nil
+	}
+
+	ids, fieldErr := st.s.l.collectionIDs(spec.scope, elementKeys)
+	if fieldErr == nil && slice {
+		var length int
+		length, fieldErr = sliceLength(spec.scope, ids)
+		ids = make([]string, length)
+		for i := range ids {
+			ids[i] = strconv.Itoa(i)
+		}
+	}
+	if fieldErr != nil {
+		st.s.errs = append(st.s.errs, fieldErr)
+		return nil, nil
+	}
+
+	if len(ids) > 0 {
+		st.changed = true
+	}
+	return &collectionState{s: st.s, sc: spec.scope}, ids
+}
+
+// Element returns the element of c identified by id.
+func (c *collectionState) Element(id string) bridge.Struct {
+	return &structState{s: c.s, sc: c.sc.element(id)}
+}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// Get reads a single value of type T. key accepts the same syntax as an env tag, so
+// options such as defaults, layouts, separators and format=bytes apply:
+//
+//	size, err := config.Get[int64]("MAX_BYTES,default=1MiB,format=bytes")
+//
+// Errors are returned as *FieldError.
+func Get[T any](key string, opts ...Option) (T, error) {
+	var value T
+
+	fieldOpts, ok, err := parseFieldOptions(key)
+	if err != nil {
+		return value, &FieldError{Field: tagKey(key), Kind: KindTag, Err: err}
+	}
+	if !ok {
+		return value, &FieldError{Kind: KindTag, Err: errors.New("env tag must start with a key")}
+	}
+	if fieldOpts.requiredIfKey != "" || len(fieldOpts.requiredWith) > 0 || fieldOpts.group != "" {
+		return value, &FieldError{Field: fieldOpts.key, Kind: KindTag, Err: errors.New("required_if, required_with and group apply only to struct fields")}
+	}
+
+	l := newLoader(opts)
+	fieldOpts = fieldOpts.withPrefix(l.prefix)
+	fieldOpts.secret = fieldOpts.secret || l.redact
+
+	target := reflect.ValueOf(&value).Elem()
+	if _, fieldErr := l.assignField(target, fieldOpts.key, fieldOpts, nil); fieldErr != nil {
+		return value, fieldErr
+	}
+	return value, nil
+}
+
+// tagKey returns the key at the start of an env tag, or "" when it has none.
+func tagKey(tag string) string {
+	parts := splitTag(tag)
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(parts[0])
+}
+
+// MustGet is like Get but panics on error.
+func MustGet[T any](key string, opts ...Option) T {
+	value, err := Get[T](key, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// Parse loads a new T, which must be a struct type, with the same rules as Load.
+func Parse[T any](opts ...Option) (T, error) {
+	var value T
+	err := Load(&value, opts...)
+	return value, err
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetParsesSingleValues(t *testing.T) {
+	source := WithSource(MapSource{
+		"PORT":       "9090",
+		"MAX_BYTES":  "4MiB",
+		"STARTED_AT": "2026-03-10",
+		"HOSTS":      "a|b",
+		"APP_MODE":   "prod",
+	})
+
+	if port, err := Get[int]("PORT", source); err != nil || port != 9090 {
+		t.Fatalf("expected port 9090, got %d (%v)", port, err)
+	}
+	if size, err := Get[int64]("MAX_BYTES,format=bytes", source); err != nil || size != 4*1024*1024 {
+		t.Fatalf("expected 4MiB, got %d (%v)", size, err)
+	}
+	if timeout, err := Get[time.Duration]("TIMEOUT,default=5s", source); err != nil || timeout != 5*time.Second {
+		t.Fatalf("expected default timeout, got %v (%v)", timeout, err)
+	}
+	startedAt, err := Get[time.Time]("STARTED_AT,layout=2006-01-02", source)
+	if err != nil || !startedAt.Equal(time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected started_at, got %v (%v)", startedAt, err)
+	}
+	if hosts, err := Get[[]string]("HOSTS,sep=|", source); err != nil || !reflect.DeepEqual(hosts, []string{"a", "b"}) {
+		t.Fatalf("expected hosts, got %v (%v)", hosts, err)
+	}
+	if mode := MustGet[string]("MODE,oneof=dev|prod", source, WithPrefix("APP_")); mode != "prod" {
+		t.Fatalf("expected prefixed mode, got %q", mode)
+	}
+}
+
+func TestGetReportsErrors(t *testing.T) {
+	source := WithSource(MapSource{"PORT": "http"})
+
+	_, err := Get[int]("PORT", source)
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Key != "PORT" || !errors.Is(err, ErrParse) {
+		t.Fatalf("expected parse error for PORT, got %v", err)
+	}
+	if _, err := Get[string]("TOKEN,required", source); !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected required error, got %v", err)
+	}
+	if _, err := Get[string](",default=x", source); !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("expected tag error, got %v", err)
+	}
+	_, err = Get[int]("PORT,bogus", source)
+	if want := `field PORT: unsupported env option "bogus"`; err == nil || err.Error() != want {
+		t.Fatalf("expected %q, got %v", want, err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected MustGet to panic")
+		}
+	}()
+	MustGet[int]("PORT", source)
+}
+
+func TestParseLoadsStructType(t *testing.T) {
+	type testConfig struct {
+		Port int `env:"PORT,default=8080"`
+	}
+
+	cfg, err := Parse[testConfig](WithSource(MapSource{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 8080 {
+		t.Fatalf("expected default port, got %d", cfg.Port)
+	}
+
+	if _, err := Parse[int](); err == nil {
+		t.Fatal("expected error for non-struct type")
+	}
+}
@@ -0,0 +1,102 @@
+// Package bridge connects package envchainrt to the loader in package config,
+// which installs the hooks below when it is initialised. It lets generated
+// loaders share the loader without config exporting it.
+package bridge
+
+import (
+	"net/url"
+	"reflect"
+	"time"
+)
+
+// Hooks installed by package config.
+var (
+	// NewSession starts a generated load. opts is a []config.Option.
+	NewSession func(source Lookuper, auto bool, opts any) Session
+	// CompileField interprets the tags of a struct field. shape is one of the
+	// envchainrt.FieldShape values.
+	CompileField func(name string, tag reflect.StructTag, anonymous bool, shape int, auto bool) Field
+	// ParseURL parses an absolute URL as config.Load does.
+	ParseURL func(raw string) (*url.URL, error)
+	// WrapElementError names the slice element or map entry that failed to decode.
+	WrapElementError func(err error, format string, args ...any) error
+)
+
+// Lookuper has the method set of config.Lookuper.
+type Lookuper interface {
+	Lookup(key string) (string, bool)
+}
+
+// Session is one call to a generated loader.
+type Session interface {
+	Root() Struct
+	Err() error
+}
+
+// Actions returned by Struct.Lookup. They match the envchainrt.Action values.
+const (
+	Skip = iota
+	Decode
+	Keep
+)
+
+// Struct is a struct being loaded. Methods returning a Struct or Collection
+// return nil when the field is not loaded.
+type Struct interface {
+	Nested(f Field, optional bool) Struct
+	Done(target any) bool
+	LoadValue(f Field, target reflect.Value)
+	Lookup(f Field, zero bool) (string, int)
+	Keep(existing string)
+	Decoded(typeName string, err error) bool
+	Collection(f Field, slice bool, elementKeys []string, existing bool) (Collection, []string)
+}
+
+// Collection is a struct slice or map field being loaded.
+type Collection interface {
+	Element(id string) Struct
+}
+
+// Field is a struct field whose tags have been compiled.
+type Field interface {
+	Err() error
+	Info() FieldInfo
+	WithoutFileKey()
+	Element() Field
+	MapKey() Field
+	Split(raw string) ([]string, error)
+	SplitMap(raw string) (map[string]string, error)
+	ParseInt(raw string, bits int) (int64, error)
+	DecodeBinary(raw string) ([]byte, error)
+	CheckString(value string) error
+	CheckCollection(length int, stringElems bool, typeName string) error
+	CheckInt(value int64, typeName string) error
+	CheckUint(value uint64, typeName string) error
+	CheckFloat(value float64, typeName string) error
+	CheckDuration(value time.Duration, typeName string) error
+	CheckOther(typeName string) error
+}
+
+// FieldInfo mirrors envchainrt.FieldInfo. Kind is one of the envchainrt.FieldKind
+// values.
+type FieldInfo struct {
+	Kind   int
+	Prefix string
+	Secret bool
+	Auto   bool
+	Tag    FieldTag
+}
+
+// FieldTag mirrors envchainrt.FieldTag.
+type FieldTag struct {
+	Key        string
+	Aliases    []string
+	Required   bool
+	Default    string
+	HasDefault bool
+	OneOf      []string
+	Layout     string
+	Format     string
+	Secret     bool
+	File       bool
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// mergeMode decides whether Load keeps values a field held before it was called.
+type mergeMode int
+
+const (
+	// mergeReplace assigns every value found, and every default, over existing values.
+	mergeReplace mergeMode = iota
+	// mergeFillZero only assigns fields that still hold their zero value.
+	mergeFillZero
+	// mergeEnvOverride assigns values found in the source over existing values, but
+	// defaults only to zero fields.
+	mergeEnvOverride
+)
+
+// WithFillZero makes Load assign only fields that still hold their zero value, so
+// values set before Load, for example from command-line flags, win over both the
+// source and defaults. A non-zero value also satisfies required. Struct slices and
+// maps that are not nil are kept whole.
+func WithFillZero() Option {
+	return func(l *loader) {
+		l.merge = mergeFillZero
+	}
+}
+
+// WithEnvOverride makes values found in the source replace values set before Load,
+// while defaults only fill fields that are still zero. A non-zero value satisfies
+// required when the source has none.
+func WithEnvOverride() Option {
+	return func(l *loader) {
+		l.merge = mergeEnvOverride
+	}
+}
+
+// keepsExisting reports whether a field keeps its current value instead of value.
+// zero reports whether the field holds its type's zero value.
+func (l *loader) keepsExisting(zero bool, value resolvedValue) bool {
+	switch l.merge {
+	case mergeFillZero:
+		return !zero
+	case mergeEnvOverride:
+		return (value.source == SourceUnset || value.source == SourceDefault) && !zero
+	default:
+		return false
+	}
+}
+
+// existingValue describes the value a field kept, for conditions that compare it.
+func existingValue(field reflect.Value) resolvedValue {
+	for field.Kind() == reflect.Pointer && !field.IsNil() {
+		field = field.Elem()
+	}
+	raw := fmt.Sprint(field.Interface())
+	if field.Kind() == reflect.String {
+		raw = field.String()
+	}
+	return resolvedValue{raw: raw, source: SourceExisting}
+}
+
+// keepsCollection reports whether a struct slice or map keeps its elements rather
+// than being replaced by those found in the source.
+func (l *loader) keepsCollection(existing bool) bool {
+	return l.merge == mergeFillZero && existing
+}
+
+// skipCollection marks the variables of each element found under sc as known, so
+// strict mode does not report them for a collection that kept its elements.
+func (l *loader) skipCollection(sc scope, elementKeys []string) {
+	lister, ok := l.source.(KeyLister)
+	if !ok || l.strict == nil {
+		return
+	}
+	for _, id := range discoverCollectionIDs(lister.Keys(), sc.prefix, elementKeys) {
+		for _, key := range elementKeys {
+			l.markKnown(sc.prefix + id + "_" + key)
+		}
+	}
+}
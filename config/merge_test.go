@@ -0,0 +1,160 @@
+package config
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+type mergeConfig struct {
+	Port      int              `env:"PORT,default=8080"`
+	Host      string           `env:"HOST,required"`
+	Timeout   time.Duration    `env:"TIMEOUT,default=5s"`
+	Mode      string           `env:"MODE,default=dev"`
+	Debug     bool             `env:"DEBUG"`
+	Token     string           `env:"TOKEN,required_if=MODE:prod"`
+	Upstreams []upstreamConfig `envPrefix:"UPSTREAMS_"`
+}
+
+func TestLoadFillZeroKeepsExistingValues(t *testing.T) {
+	source := MapSource{
+		"PORT":             "not a number",
+		"HOST":             "env-host",
+		"DEBUG":            "true",
+		"UPSTREAMS_0_HOST": "env-upstream",
+	}
+
+	cfg := mergeConfig{
+		Port:      9000,
+		Timeout:   time.Second,
+		Mode:      "prod",
+		Token:     "flag-token",
+		Upstreams: []upstreamConfig{{Host: "flag-upstream"}},
+	}
+	var report Report
+	if err := LoadFrom(&cfg, source, WithFillZero(), WithStrict(""), WithReport(&report)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := mergeConfig{
+		Port:      9000,
+		Host:      "env-host",
+		Timeout:   time.Second,
+		Mode:      "prod",
+		Debug:     true,
+		Token:     "flag-token",
+		Upstreams: []upstreamConfig{{Host: "flag-upstream"}},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("expected %+v, got %+v", want, cfg)
+	}
+
+	var sources []Source
+	for _, entry := range report.Entries {
+		sources = append(sources, entry.Source)
+	}
+	wantSources := []Source{SourceExisting, SourceEnv, SourceExisting, SourceExisting, SourceEnv, SourceExisting}
+	if !slices.Equal(sources, wantSources) {
+		t.Fatalf("expected sources %v, got %v", wantSources, sources)
+	}
+	if report.Entries[0].Value != "" {
+		t.Fatalf("expected existing values to be left out of the report, got %q", report.Entries[0].Value)
+	}
+}
+
+func TestLoadFillZeroDoesNotReadKeptFields(t *testing.T) {
+	type testConfig struct {
+		Port  int    `env:"HTTP_PORT,alias=PORT"`
+		Token string `env:"TOKEN"`
+	}
+
+	source := MapSource{
+		"PORT":       "9090",
+		"TOKEN_FILE": filepath.Join(t.TempDir(), "missing"),
+	}
+	var warnings []Warning
+	cfg := testConfig{Port: 8000, Token: "flag-token"}
+	err := LoadFrom(&cfg, source, WithFillZero(), WithStrict(""), WithWarningHandler(func(w Warning) {
+		warnings = append(warnings, w)
+	}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 8000 || cfg.Token != "flag-token" {
+		t.Fatalf("expected existing values to be kept, got %+v", cfg)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("expected no alias warnings for kept fields, got %+v", warnings)
+	}
+}
+
+func TestLoadFillZeroSatisfiesRequired(t *testing.T) {
+	cfg := mergeConfig{Host: "flag-host"}
+	if err := LoadFrom(&cfg, MapSource{}, WithFillZero()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Host != "flag-host" || cfg.Port != 8080 || cfg.Mode != "dev" {
+		t.Fatalf("expected existing host and defaults, got %+v", cfg)
+	}
+}
+
+func TestLoadEnvOverride(t *testing.T) {
+	source := MapSource{
+		"PORT":             "8081",
+		"UPSTREAMS_0_HOST": "env-upstream",
+	}
+
+	cfg := mergeConfig{
+		Port:      9000,
+		Host:      "flag-host",
+		Timeout:   time.Second,
+		Upstreams: []upstreamConfig{{Host: "flag-upstream"}},
+	}
+	if err := LoadFrom(&cfg, source, WithEnvOverride()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := mergeConfig{
+		Port:      8081,
+		Host:      "flag-host",
+		Timeout:   time.Second,
+		Mode:      "dev",
+		Upstreams: []upstreamConfig{{Host: "env-upstream", Port: 80, Weight: 1}},
+	}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("expected %+v, got %+v", want, cfg)
+	}
+}
+
+func TestLoadEnvOverrideReportsInvalidValues(t *testing.T) {
+	cfg := mergeConfig{Port: 9000, Host: "flag-host"}
+	err := LoadFrom(&cfg, MapSource{"PORT": "not a number"}, WithEnvOverride())
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) || len(loadErr.Errors) != 1 || loadErr.Errors[0].Kind != KindParse {
+		t.Fatalf("expected a parse error for PORT, got %v", err)
+	}
+	if cfg.Port != 9000 {
+		t.Fatalf("expected the invalid value to leave Port untouched, got %d", cfg.Port)
+	}
+}
+
+func TestLoadReplacesExistingValuesByDefault(t *testing.T) {
+	cfg := mergeConfig{Port: 9000, Host: "flag-host"}
+	if err := LoadFrom(&cfg, MapSource{"HOST": "env-host"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 8080 || cfg.Host != "env-host" {
+		t.Fatalf("expected env values and defaults to replace existing values, got %+v", cfg)
+	}
+}
+
+func TestLoadMergeConditionsSeeExistingValues(t *testing.T) {
+	cfg := mergeConfig{Host: "flag-host", Mode: "prod"}
+	err := LoadFrom(&cfg, MapSource{}, WithFillZero())
+	if !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected Token to be required by the existing mode, got %v", err)
+	}
+}
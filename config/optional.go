@@ -0,0 +1,57 @@
+package config
+
+import "reflect"
+
+// Optional holds a value of type T and records whether Load set it, distinguishing
+// an unset key from one set to the zero value.
+//
+//	type config struct {
+//		Retries config.Optional[int] `env:"RETRIES"`
+//	}
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// Some returns an Optional holding value.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, set: true}
+}
+
+// Get returns the value and whether it was set.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+// IsSet reports whether the value was set.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// ValueOr returns the value if set, and fallback otherwise.
+func (o Optional[T]) ValueOr(fallback T) T {
+	if !o.set {
+		return fallback
+	}
+	return o.value
+}
+
+// optionalValue is implemented by *Optional[T] so Load can fill it via reflection.
+type optionalValue interface {
+	optionalTarget() reflect.Value
+	markSet()
+}
+
+func (o *Optional[T]) optionalTarget() reflect.Value {
+	return reflect.ValueOf(&o.value).Elem()
+}
+
+func (o *Optional[T]) markSet() {
+	o.set = true
+}
+
+var optionalValueType = reflect.TypeOf((*optionalValue)(nil)).Elem()
+
+func isOptional(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(optionalValueType)
+}
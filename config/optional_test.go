@@ -0,0 +1,88 @@
+package config
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestLoadLeavesUnsetPointerScalarsNil(t *testing.T) {
+	type testConfig struct {
+		Retries   *int           `env:"RETRIES"`
+		Debug     *bool          `env:"DEBUG"`
+		Timeout   *time.Duration `env:"TIMEOUT,default=5s"`
+		StartedAt *time.Time     `env:"STARTED_AT,layout=2006-01-02"`
+		Ratio     *float64       `env:"RATIO,max=1"`
+		Name      *string        `env:"NAME"`
+		Endpoint  *url.URL       `env:"ENDPOINT"`
+	}
+
+	source := MapSource{
+		"RETRIES":    "0",
+		"DEBUG":      "false",
+		"STARTED_AT": "2026-03-10",
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Retries == nil || *cfg.Retries != 0 {
+		t.Fatalf("expected explicit zero retries, got %v", cfg.Retries)
+	}
+	if cfg.Debug == nil || *cfg.Debug {
+		t.Fatalf("expected explicit false debug, got %v", cfg.Debug)
+	}
+	if cfg.Timeout == nil || *cfg.Timeout != 5*time.Second {
+		t.Fatalf("expected default timeout, got %v", cfg.Timeout)
+	}
+	if cfg.StartedAt == nil || !cfg.StartedAt.Equal(time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected started_at parsed with layout, got %v", cfg.StartedAt)
+	}
+	if cfg.Ratio != nil || cfg.Name != nil || cfg.Endpoint != nil {
+		t.Fatalf("expected unset pointers to stay nil, got %+v", cfg)
+	}
+
+	err := LoadFrom(&cfg, MapSource{"RATIO": "1.5"})
+	if err == nil {
+		t.Fatal("expected validation error through pointer")
+	}
+}
+
+func TestLoadRecordsOptionalPresence(t *testing.T) {
+	type testConfig struct {
+		Retries Optional[int]           `env:"RETRIES"`
+		Timeout Optional[time.Duration] `env:"TIMEOUT"`
+		Workers Optional[int]           `env:"WORKERS,default=4,min=1"`
+		Labels  Optional[[]string]      `env:"LABELS"`
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, MapSource{"RETRIES": "0", "LABELS": "a,b"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if retries, ok := cfg.Retries.Get(); !ok || retries != 0 {
+		t.Fatalf("expected retries to be set to 0, got %d (set=%v)", retries, ok)
+	}
+	if cfg.Timeout.IsSet() {
+		t.Fatal("expected timeout to be unset")
+	}
+	if got := cfg.Timeout.ValueOr(time.Minute); got != time.Minute {
+		t.Fatalf("expected fallback timeout, got %v", got)
+	}
+	if got := cfg.Workers.ValueOr(0); got != 4 {
+		t.Fatalf("expected default workers, got %d", got)
+	}
+	if labels, _ := cfg.Labels.Get(); len(labels) != 2 {
+		t.Fatalf("expected labels, got %v", labels)
+	}
+	if !Some(3).IsSet() || Some(3).ValueOr(0) != 3 {
+		t.Fatal("expected Some to hold a set value")
+	}
+
+	if err := LoadFrom(&cfg, MapSource{"WORKERS": "0"}); err == nil {
+		t.Fatal("expected validation error through Optional")
+	}
+}
@@ -0,0 +1,100 @@
+package config
+
+import "reflect"
+
+// Option customizes a single Load call.
+type Option func(*loader)
+
+// loader carries the per-call state shared by every field visited during Load.
+type loader struct {
+	source      Lookuper
+	parsers     map[reflect.Type]parserFunc
+	redact      bool
+	expand      bool
+	auto        bool
+	onWarning   func(Warning)
+	report      *Report
+	prefix      string
+	maxFileSize int64
+	strict      *strictMode
+	merge       mergeMode
+	// known holds every key looked up in source or declared by a loaded field, so
+	// strict mode can tell which variables no field reads. It is only kept in
+	// strict mode.
+	known map[string]struct{}
+}
+
+func newLoader(opts []Option) *loader {
+	l := &loader{maxFileSize: defaultMaxFileSize}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(l)
+		}
+	}
+	if l.source == nil {
+		l.source = OSSource{}
+	}
+	return l
+}
+
+// WithRedactedValues treats every field as `secret`, keeping raw values out of all
+// errors produced by Load.
+func WithRedactedValues() Option {
+	return func(l *loader) {
+		l.redact = true
+	}
+}
+
+// WithPrefix prepends prefix to every key read by Load, including keys beneath
+// nested structs with their own envPrefix.
+func WithPrefix(prefix string) Option {
+	return func(l *loader) {
+		l.prefix = prefix
+	}
+}
+
+// WithMaxFileSize limits how many bytes Load reads from a file named by a `file`
+// field or a KEY_FILE variable. The default is 1 MiB.
+func WithMaxFileSize(n int64) Option {
+	return func(l *loader) {
+		l.maxFileSize = n
+	}
+}
+
+// WithExpand enables ${VAR} and ${VAR:-fallback} expansion for every field, as if
+// each were tagged `expand`.
+func WithExpand() Option {
+	return func(l *loader) {
+		l.expand = true
+	}
+}
+
+// WithWarningHandler registers fn to receive non-fatal diagnostics, such as use of
+// a deprecated alias, during Load.
+func WithWarningHandler(fn func(Warning)) Option {
+	return func(l *loader) {
+		l.onWarning = fn
+	}
+}
+
+// lookup reads key from the source and remembers that it was consulted.
+func (l *loader) lookup(key string) (string, bool) {
+	l.markKnown(key)
+	return l.source.Lookup(key)
+}
+
+func (l *loader) markKnown(key string) {
+	if l.strict == nil {
+		return
+	}
+	if l.known == nil {
+		l.known = map[string]struct{}{}
+	}
+	l.known[key] = struct{}{}
+}
+
+func (l *loader) warn(w Warning) {
+	if l.onWarning != nil {
+		l.onWarning(w)
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"maps"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// ParseOptions exposes the env tag options of the field being parsed to custom parsers.
+type ParseOptions struct {
+	Key    string
+	Layout string
+	Format string
+}
+
+// ParserFunc converts a raw environment value into a T.
+type ParserFunc[T any] func(raw string, opts ParseOptions) (T, error)
+
+type parserFunc func(raw string, opts ParseOptions) (reflect.Value, error)
+
+// parsers holds the registered parsers. RegisterParser replaces the map rather than
+// modifying it, so Load reads it without locking.
+var (
+	parsersMu sync.Mutex
+	parsers   atomic.Pointer[map[reflect.Type]parserFunc]
+)
+
+// RegisterParser installs fn as the parser for fields of type T in every Load call.
+// Registered parsers are consulted before the built-in rules and also apply to
+// slice elements and map values of type T. Passing a nil fn removes the parser.
+func RegisterParser[T any](fn ParserFunc[T]) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+
+	registered := map[reflect.Type]parserFunc{}
+	if current := parsers.Load(); current != nil {
+		registered = maps.Clone(*current)
+	}
+
+	t := reflect.TypeFor[T]()
+	if fn == nil {
+		delete(registered, t)
+	} else {
+		registered[t] = wrapParser(fn)
+	}
+	parsers.Store(&registered)
+}
+
+// WithParser installs fn as the parser for fields of type T for a single Load call.
+// It takes precedence over parsers installed with RegisterParser.
+func WithParser[T any](fn ParserFunc[T]) Option {
+	return func(l *loader) {
+		if fn == nil {
+			return
+		}
+		if l.parsers == nil {
+			l.parsers = map[reflect.Type]parserFunc{}
+		}
+		l.parsers[reflect.TypeFor[T]()] = wrapParser(fn)
+	}
+}
+
+func wrapParser[T any](fn ParserFunc[T]) parserFunc {
+	return func(raw string, opts ParseOptions) (reflect.Value, error) {
+		value, err := fn(raw, opts)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		result := reflect.New(reflect.TypeFor[T]()).Elem()
+		result.Set(reflect.ValueOf(&value).Elem())
+		return result, nil
+	}
+}
+
+func (l *loader) parserFor(t reflect.Type) (parserFunc, bool) {
+	if parse, ok := l.parsers[t]; ok {
+		return parse, true
+	}
+
+	registered := parsers.Load()
+	if registered == nil {
+		return nil, false
+	}
+	parse, ok := (*registered)[t]
+	return parse, ok
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"errors"
+	"net/netip"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLoadUsesRegisteredParsers(t *testing.T) {
+	RegisterParser(func(raw string, _ ParseOptions) (netip.Addr, error) {
+		return netip.ParseAddr(raw)
+	})
+	t.Cleanup(func() { RegisterParser[netip.Addr](nil) })
+
+	type testConfig struct {
+		Addr    netip.Addr            `env:"ADDR"`
+		Peers   []netip.Addr          `env:"PEERS,sep=|"`
+		Gateway map[string]netip.Addr `env:"GATEWAYS"`
+	}
+
+	t.Setenv("ADDR", "10.0.0.1")
+	t.Setenv("PEERS", "10.0.0.2|10.0.0.3")
+	t.Setenv("GATEWAYS", "east=10.1.0.1,west=10.2.0.1")
+
+	var cfg testConfig
+	if err := Load(&cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Addr != netip.MustParseAddr("10.0.0.1") {
+		t.Fatalf("expected addr 10.0.0.1, got %v", cfg.Addr)
+	}
+	if len(cfg.Peers) != 2 || cfg.Peers[1] != netip.MustParseAddr("10.0.0.3") {
+		t.Fatalf("expected two peers, got %v", cfg.Peers)
+	}
+	if cfg.Gateway["west"] != netip.MustParseAddr("10.2.0.1") {
+		t.Fatalf("expected west gateway 10.2.0.1, got %v", cfg.Gateway)
+	}
+}
+
+func TestLoadPrefersPerCallParsers(t *testing.T) {
+	RegisterParser(func(string, ParseOptions) (*regexp.Regexp, error) {
+		return nil, errors.New("global parser should not be used")
+	})
+	t.Cleanup(func() { RegisterParser[*regexp.Regexp](nil) })
+
+	type testConfig struct {
+		Match *regexp.Regexp `env:"MATCH"`
+		Level string         `env:"LEVEL,format=upper"`
+	}
+
+	t.Setenv("MATCH", "^api-[0-9]+$")
+	t.Setenv("LEVEL", "warn")
+
+	var cfg testConfig
+	err := Load(&cfg,
+		WithParser(func(raw string, _ ParseOptions) (*regexp.Regexp, error) {
+			return regexp.Compile(raw)
+		}),
+		WithParser(func(raw string, opts ParseOptions) (string, error) {
+			if opts.Format == "upper" {
+				return strings.ToUpper(raw), nil
+			}
+			return raw, nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Match == nil || !cfg.Match.MatchString("api-42") {
+		t.Fatalf("expected compiled regexp, got %v", cfg.Match)
+	}
+	if cfg.Level != "WARN" {
+		t.Fatalf("expected parser to receive tag options, got %q", cfg.Level)
+	}
+}
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"reflect"
+	"sync"
+)
+
+// structPlan holds the compiled tags of every field of a struct type, in field
+// order.
+type structPlan struct {
+	fields []fieldPlan
+}
+
+type planKey struct {
+	t    reflect.Type
+	auto bool
+}
+
+// planCache maps a planKey to its *structPlan. Plans depend only on the struct
+// type and on whether keys are derived, so they are shared by every Load call.
+var planCache sync.Map
+
+// planFor returns the compiled plan for struct type t, compiling it on first use.
+// It is safe for concurrent use.
+func planFor(t reflect.Type, auto bool) *structPlan {
+	key := planKey{t: t, auto: auto}
+	if plan, ok := planCache.Load(key); ok {
+		return plan.(*structPlan)
+	}
+
+	plan := &structPlan{fields: make([]fieldPlan, t.NumField())}
+	for i := range plan.fields {
+		plan.fields[i] = compileField(t.Field(i), auto)
+	}
+	claimFileKeys(plan.fields)
+	actual, _ := planCache.LoadOrStore(key, plan)
+	return actual.(*structPlan)
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type benchDBConfig struct {
+	Host     string        `env:"HOST,required"`
+	Port     int           `env:"PORT,default=5432,min=1,max=65535"`
+	User     string        `env:"USER,default=app,pattern=^[a-z_]+$"`
+	Password string        `env:"PASSWORD,secret"`
+	Timeout  time.Duration `env:"TIMEOUT,default=5s"`
+}
+
+type benchConfig struct {
+	Name     string            `env:"NAME,default=api"`
+	Mode     string            `env:"MODE,default=dev,oneof=dev|staging|prod"`
+	Port     int               `env:"PORT,default=8080,alias=LISTEN_PORT"`
+	Hosts    []string          `env:"HOSTS,default=a|b|c,sep=|"`
+	Labels   map[string]string `env:"LABELS,default=team=core"`
+	MaxBytes int64             `env:"MAX_BYTES,default=1MiB,format=bytes"`
+	Primary  benchDBConfig     `envPrefix:"PRIMARY_"`
+	Replica  *benchDBConfig    `envPrefix:"REPLICA_"`
+}
+
+type benchValuesConfig struct {
+	Name    string            `env:"NAME"`
+	Debug   bool              `env:"DEBUG"`
+	Workers int               `env:"WORKERS"`
+	Limit   uint32            `env:"LIMIT"`
+	Ratio   float64           `env:"RATIO"`
+	Timeout time.Duration     `env:"TIMEOUT"`
+	Retries *int              `env:"RETRIES"`
+	Ports   []int             `env:"PORTS"`
+	Weights map[string]int    `env:"WEIGHTS"`
+	Token   []byte            `env:"TOKEN,format=base64"`
+	Extra   map[string]string `env:"EXTRA,format=json"`
+}
+
+var benchValuesSource = MapSource{
+	"NAME":    "api",
+	"DEBUG":   "true",
+	"WORKERS": "8",
+	"LIMIT":   "4096",
+	"RATIO":   "0.75",
+	"TIMEOUT": "30s",
+	"RETRIES": "3",
+	"PORTS":   "8080,8081,8082,8083",
+	"WEIGHTS": "a=1,b=2,c=3",
+	"TOKEN":   "c2VjcmV0",
+	"EXTRA":   `{"region":"eu"}`,
+}
+
+var benchSource = MapSource{
+	"APP_PRIMARY_HOST":     "primary.internal",
+	"APP_PRIMARY_PASSWORD": "s3cret",
+	"APP_REPLICA_HOST":     "replica.internal",
+	"APP_MODE":             "prod",
+}
+
+func BenchmarkLoad(b *testing.B) {
+	load := func(b *testing.B) {
+		var cfg benchConfig
+		if err := LoadFrom(&cfg, benchSource, WithPrefix("APP_")); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			load(b)
+		}
+	})
+	b.Run("uncached", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			planCache.Clear()
+			load(b)
+		}
+	})
+	// values sets every field from the source, so it mostly measures the setters
+	// compiled into the plan rather than key lookups and defaults.
+	b.Run("values", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var cfg benchValuesConfig
+			if err := LoadFrom(&cfg, benchValuesSource); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkLoadParallel(b *testing.B) {
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var cfg benchConfig
+			if err := LoadFrom(&cfg, benchSource, WithPrefix("APP_")); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestPlanForCachesPerTypeAndAutoSetting(t *testing.T) {
+	configType := reflect.TypeOf(benchConfig{})
+	if planFor(configType, false) != planFor(configType, false) {
+		t.Fatal("expected the plan to be cached")
+	}
+	if planFor(configType, false) == planFor(configType, true) {
+		t.Fatal("expected separate plans with and without derived keys")
+	}
+}
+
+func TestLoadIsSafeForConcurrentUse(t *testing.T) {
+	planCache.Clear()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var cfg benchConfig
+			if err := LoadFrom(&cfg, benchSource, WithPrefix("APP_")); err != nil {
+				errs <- err
+				return
+			}
+			if cfg.Primary.Host != "primary.internal" || cfg.Replica == nil || cfg.Mode != "prod" {
+				errs <- fmt.Errorf("unexpected config %+v", cfg)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestDescribeDoesNotShareCachedSlices(t *testing.T) {
+	vars, err := Describe(&benchConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vars[1].OneOf[0] = "mutated"
+
+	vars, err = Describe(&benchConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if vars[1].OneOf[0] != "dev" {
+		t.Fatalf("expected cached options to be unaffected, got %v", vars[1].OneOf)
+	}
+}
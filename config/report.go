@@ -0,0 +1,83 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// Source identifies where a field's value came from.
+type Source string
+
+const (
+	SourceEnv     Source = "env"
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceAlias   Source = "alias"
+	SourceUnset   Source = "unset"
+	// SourceExisting marks a field that kept the value it held before Load, under
+	// WithFillZero or WithEnvOverride.
+	SourceExisting Source = "existing"
+)
+
+// ReportEntry records the provenance of a single field.
+type ReportEntry struct {
+	// Field is the dotted Go path of the field, such as "DB.Port".
+	Field string
+	// Key is the variable that supplied the value: the field's key, its KEY_FILE
+	// companion, or the alias that was used.
+	Key    string
+	Source Source
+	// Value is the raw value as found, before variable references are expanded,
+	// or "[REDACTED]" for secret fields and values read from files. It is empty
+	// when Source is SourceUnset or SourceExisting.
+	Value string
+}
+
+// Report lists where each field loaded by Load got its value.
+type Report struct {
+	Entries []ReportEntry
+}
+
+// WithReport fills r with the provenance of every tagged field visited by Load.
+// Existing entries are discarded.
+func WithReport(r *Report) Option {
+	return func(l *loader) {
+		if r != nil {
+			r.Entries = nil
+		}
+		l.report = r
+	}
+}
+
+func (l *loader) record(fieldName string, opts fieldOptions, value resolvedValue) {
+	if l.report == nil {
+		return
+	}
+
+	entry := ReportEntry{Field: fieldName, Key: value.key, Source: value.source}
+	switch {
+	case value.source == SourceUnset, value.source == SourceExisting:
+	case opts.secret || l.redact || value.file != "":
+		entry.Value = redactedValue
+	default:
+		entry.Value = value.display()
+	}
+	l.report.Entries = append(l.report.Entries, entry)
+}
+
+// String renders the report as an aligned table suitable for startup logs.
+func (r *Report) String() string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tKEY\tSOURCE\tVALUE")
+	for _, entry := range r.Entries {
+		value := entry.Value
+		if entry.Source != SourceUnset && entry.Source != SourceExisting && value != redactedValue {
+			value = fmt.Sprintf("%q", value)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", entry.Field, entry.Key, entry.Source, value)
+	}
+	_ = tw.Flush()
+	return b.String()
+}
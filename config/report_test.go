@@ -0,0 +1,83 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadFillsProvenanceReport(t *testing.T) {
+	type testConfig struct {
+		Timeout  string `env:"TIMEOUT,default=5s"`
+		Port     int    `env:"HTTP_PORT,alias=PORT"`
+		Host     string `env:"HOST"`
+		Password string `env:"PASSWORD,secret"`
+		Token    string `env:"TOKEN"`
+		Region   string `env:"REGION"`
+	}
+
+	source := MapSource{
+		"PORT":       "9090",
+		"HOST":       "0.0.0.0",
+		"PASSWORD":   "hunter2",
+		"TOKEN_FILE": writeValueFile(t, "token", "abc\n"),
+	}
+
+	report := Report{Entries: []ReportEntry{{Field: "stale"}}}
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source, WithReport(&report)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []ReportEntry{
+		{Field: "Timeout", Key: "TIMEOUT", Source: SourceDefault, Value: "5s"},
+		{Field: "Port", Key: "PORT", Source: SourceAlias, Value: "9090"},
+		{Field: "Host", Key: "HOST", Source: SourceEnv, Value: "0.0.0.0"},
+		{Field: "Password", Key: "PASSWORD", Source: SourceEnv, Value: "[REDACTED]"},
+		{Field: "Token", Key: "TOKEN_FILE", Source: SourceFile, Value: "[REDACTED]"},
+		{Field: "Region", Key: "REGION", Source: SourceUnset},
+	}
+	if !reflect.DeepEqual(report.Entries, want) {
+		t.Fatalf("expected entries:\n%+v\ngot:\n%+v", want, report.Entries)
+	}
+
+	got := report.String()
+	for _, line := range []string{
+		"FIELD     KEY         SOURCE   VALUE",
+		`Timeout   TIMEOUT     default  "5s"`,
+		`Password  PASSWORD    env      [REDACTED]`,
+		`Region    REGION      unset`,
+	} {
+		if !strings.Contains(got, line) {
+			t.Fatalf("expected report to contain %q, got:\n%s", line, got)
+		}
+	}
+	if strings.Contains(got, "hunter2") || strings.Contains(got, "abc") {
+		t.Fatalf("expected secrets to be redacted, got:\n%s", got)
+	}
+}
+
+func TestReportShowsValuesBeforeExpansion(t *testing.T) {
+	type testConfig struct {
+		Password    string `env:"DB_PASSWORD,secret"`
+		DatabaseURL string `env:"DATABASE_URL,expand"`
+	}
+
+	source := MapSource{
+		"DB_PASSWORD":  "hunter2",
+		"DATABASE_URL": "postgres://u:${DB_PASSWORD}@h/db",
+	}
+
+	var report Report
+	if err := LoadFrom(&testConfig{}, source, WithReport(&report)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := report.String()
+	if strings.Contains(got, "hunter2") {
+		t.Fatalf("expanded secret leaked into the report:\n%s", got)
+	}
+	if report.Entries[1].Value != "postgres://u:${DB_PASSWORD}@h/db" {
+		t.Fatalf("expected the unexpanded value, got %q", report.Entries[1].Value)
+	}
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Lookuper resolves configuration keys to raw values.
+type Lookuper interface {
+	// Lookup returns the value stored under key and whether the key is set.
+	Lookup(key string) (string, bool)
+}
+
+// OSSource looks keys up in the process environment.
+type OSSource struct{}
+
+// Lookup implements Lookuper using os.LookupEnv.
+func (OSSource) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// MapSource looks keys up in a fixed map.
+type MapSource map[string]string
+
+// Lookup implements Lookuper.
+func (m MapSource) Lookup(key string) (string, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+// MultiSource consults each source in order and returns the first value found.
+type MultiSource []Lookuper
+
+// Lookup implements Lookuper.
+func (m MultiSource) Lookup(key string) (string, bool) {
+	for _, source := range m {
+		if source == nil {
+			continue
+		}
+		if value, ok := source.Lookup(key); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// LoadFrom populates a struct pointer from source using the same env tag rules as Load.
+func LoadFrom(target any, source Lookuper, opts ...Option) error {
+	return Load(target, append(opts, WithSource(source))...)
+}
+
+// WithSource makes Load read values from source instead of the process environment.
+func WithSource(source Lookuper) Option {
+	return func(l *loader) {
+		l.source = source
+	}
+}
+
+// KeyLister is implemented by sources that can enumerate their keys. Load needs it
+// to discover the elements of struct slices and maps.
+type KeyLister interface {
+	Keys() []string
+}
+
+// Keys implements KeyLister using os.Environ.
+func (OSSource) Keys() []string {
+	environ := os.Environ()
+	keys := make([]string, 0, len(environ))
+	for _, entry := range environ {
+		if key, _, ok := strings.Cut(entry, "="); ok && key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Keys implements KeyLister.
+func (m MapSource) Keys() []string {
+	return slices.Collect(maps.Keys(m))
+}
+
+// Keys implements KeyLister with the union of the keys of every source that
+// implements KeyLister.
+func (m MultiSource) Keys() []string {
+	seen := map[string]struct{}{}
+	var keys []string
+	for _, source := range m {
+		lister, ok := source.(KeyLister)
+		if !ok {
+			continue
+		}
+		for _, key := range lister.Keys() {
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+var (
+	_ Lookuper  = OSSource{}
+	_ Lookuper  = MapSource(nil)
+	_ Lookuper  = MultiSource(nil)
+	_ KeyLister = OSSource{}
+	_ KeyLister = MapSource(nil)
+	_ KeyLister = MultiSource(nil)
+)
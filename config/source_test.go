@@ -0,0 +1,60 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadFromReadsMapSource(t *testing.T) {
+	type testConfig struct {
+		Port    int           `env:"PORT,default=8080"`
+		Timeout time.Duration `env:"TIMEOUT,required"`
+	}
+
+	t.Setenv("PORT", "1111")
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, MapSource{"TIMEOUT": "3s"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cfg.Port != 8080 {
+		t.Fatalf("expected map source to ignore process env, got port %d", cfg.Port)
+	}
+	if cfg.Timeout != 3*time.Second {
+		t.Fatalf("expected timeout 3s, got %v", cfg.Timeout)
+	}
+}
+
+func TestMultiSourceUsesFirstMatch(t *testing.T) {
+	type testConfig struct {
+		Host string `env:"HOST"`
+		Port int    `env:"PORT"`
+		Name string `env:"NAME,required"`
+	}
+
+	t.Setenv("PORT", "9090")
+
+	source := MultiSource{
+		MapSource{"HOST": "override"},
+		OSSource{},
+		MapSource{"HOST": "fallback", "PORT": "1"},
+	}
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, source)
+	if err == nil {
+		t.Fatal("expected required error for NAME")
+	}
+	if got := err.Error(); !strings.Contains(got, `environment variable "NAME" is required`) {
+		t.Fatalf("expected required error for NAME, got %q", got)
+	}
+
+	if cfg.Host != "override" {
+		t.Fatalf("expected first source to win, got %q", cfg.Host)
+	}
+	if cfg.Port != 9090 {
+		t.Fatalf("expected process env before later sources, got %d", cfg.Port)
+	}
+}
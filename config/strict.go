@@ -0,0 +1,127 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// strictMode configures the check for variables that no field reads.
+type strictMode struct {
+	prefix string
+	warn   bool
+}
+
+// WithStrict makes Load fail for every variable in the source that starts with
+// prefix but is not read by any field, suggesting the closest known key. A key,
+// its KEY_FILE companion and its aliases all count as read. The source must
+// implement KeyLister. An empty prefix checks every variable, which with OSSource
+// includes unrelated ones such as PATH and HOME, so use it only with sources that
+// hold nothing but the application's variables.
+func WithStrict(prefix string) Option {
+	return func(l *loader) {
+		l.strict = &strictMode{prefix: prefix}
+	}
+}
+
+// WithStrictWarnings is like WithStrict but reports unknown variables as warnings
+// through the handler registered with WithWarningHandler instead of failing Load.
+func WithStrictWarnings(prefix string) Option {
+	return func(l *loader) {
+		l.strict = &strictMode{prefix: prefix, warn: true}
+	}
+}
+
+// checkUnknownKeys reports the variables under the strict prefix that no field
+// declares and Load never looked up. It must run after every field has loaded.
+func (l *loader) checkUnknownKeys() []*FieldError {
+	if l.strict == nil {
+		return nil
+	}
+
+	lister, ok := l.source.(KeyLister)
+	if !ok {
+		return []*FieldError{{Kind: KindTag, Err: errors.New("strict mode requires a source that can list its keys")}}
+	}
+
+	known := make([]string, 0, len(l.known))
+	for key := range l.known {
+		if strings.HasPrefix(key, l.strict.prefix) {
+			known = append(known, key)
+		}
+	}
+	slices.Sort(known)
+
+	var unknown []string
+	for _, key := range lister.Keys() {
+		if _, ok := l.known[key]; !ok && strings.HasPrefix(key, l.strict.prefix) {
+			unknown = append(unknown, key)
+		}
+	}
+	slices.Sort(unknown)
+
+	var errs []*FieldError
+	for _, key := range unknown {
+		message := fmt.Sprintf("unknown environment variable %q", key)
+		if suggestion, ok := closestKey(key, known); ok {
+			message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+
+		if l.strict.warn {
+			l.warn(Warning{Key: key, Message: message})
+			continue
+		}
+		errs = append(errs, &FieldError{Key: key, Kind: KindUnknown, Err: &kindError{msg: message, sentinel: ErrUnknownKey}})
+	}
+	return errs
+}
+
+// closestKey returns the candidate with the smallest edit distance to key, if it is
+// close enough to be a plausible typo.
+func closestKey(key string, candidates []string) (string, bool) {
+	best, bestDistance := "", -1
+	for _, candidate := range candidates {
+		distance := editDistance(key, candidate)
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	if bestDistance < 0 || bestDistance > max(2, len(key)/4) {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+// declare marks every key a value field reads as known: its key, the KEY_FILE
+// companion and its aliases, whether or not they were looked up.
+func (l *loader) declare(opts fieldOptions) {
+	l.markKnown(opts.key)
+	if !opts.file && !opts.noFileKey {
+		l.markKnown(opts.key + fileKeySuffix)
+	}
+	for _, alias := range opts.aliases {
+		l.markKnown(alias)
+	}
+}
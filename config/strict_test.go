@@ -0,0 +1,143 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestLoadStrictReportsUnknownKeys(t *testing.T) {
+	type dbConfig struct {
+		Host string `env:"HOST"`
+	}
+	type testConfig struct {
+		Timeout   string           `env:"TIMEOUT,default=5s"`
+		Token     string           `env:"TOKEN"`
+		Port      int              `env:"PORT,alias=LISTEN_PORT"`
+		URL       string           `env:"URL,expand"`
+		DB        dbConfig         `envPrefix:"DB_"`
+		Upstreams []upstreamConfig `envPrefix:"UPSTREAMS_"`
+	}
+
+	source := MapSource{
+		"APP_TIMOUT":           "10s",
+		"APP_TOKEN_FILE":       writeValueFile(t, "token", "t"),
+		"APP_LISTEN_PORT":      "8080",
+		"APP_URL":              "http://${APP_HOSTNAME}",
+		"APP_HOSTNAME":         "api.internal",
+		"APP_DB_HOST":          "db",
+		"APP_DB_HOTS":          "typo",
+		"APP_UPSTREAMS_0_HOST": "a",
+		"APP_UPSTREAMS_X_HOST": "not an index",
+		"APP_COMPLETELY_OTHER": "1",
+		"OTHER_TIMEOUT":        "ignored",
+	}
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, source, WithPrefix("APP_"), WithStrict("APP_"))
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected *LoadError, got %T (%v)", err, err)
+	}
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("expected ErrUnknownKey, got %v", err)
+	}
+
+	var got []string
+	for _, fieldErr := range loadErr.Errors {
+		if fieldErr.Kind != KindUnknown {
+			t.Fatalf("expected unknown key errors only, got %v", fieldErr)
+		}
+		got = append(got, fieldErr.Error())
+	}
+	want := []string{
+		`unknown environment variable "APP_COMPLETELY_OTHER"`,
+		`unknown environment variable "APP_DB_HOTS" (did you mean "APP_DB_HOST"?)`,
+		`unknown environment variable "APP_TIMOUT" (did you mean "APP_TIMEOUT"?)`,
+		`unknown environment variable "APP_UPSTREAMS_X_HOST" (did you mean "APP_UPSTREAMS_0_HOST"?)`,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("expected errors:\n%q\ngot:\n%q", want, got)
+	}
+	if cfg.Token != "t" || cfg.Port != 8080 || cfg.URL != "http://api.internal" || cfg.DB.Host != "db" {
+		t.Fatalf("expected known fields to load, got %+v", cfg)
+	}
+}
+
+func TestLoadStrictWarnings(t *testing.T) {
+	type testConfig struct {
+		Timeout string `env:"TIMEOUT"`
+	}
+
+	var warnings []Warning
+	err := LoadFrom(&testConfig{}, MapSource{"APP_TIMOUT": "10s"},
+		WithPrefix("APP_"),
+		WithStrictWarnings("APP_"),
+		WithWarningHandler(func(w Warning) { warnings = append(warnings, w) }),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Warning{{Key: "APP_TIMOUT", Message: `unknown environment variable "APP_TIMOUT" (did you mean "APP_TIMEOUT"?)`}}
+	if !slices.Equal(warnings, want) {
+		t.Fatalf("expected warnings %+v, got %+v", want, warnings)
+	}
+	if got := warnings[0].String(); got != want[0].Message {
+		t.Fatalf("expected warning without field, got %q", got)
+	}
+}
+
+func TestLoadStrictRequiresKeyLister(t *testing.T) {
+	type testConfig struct {
+		Timeout string `env:"TIMEOUT"`
+	}
+
+	source := lookupFunc(func(string) (string, bool) { return "", false })
+	err := LoadFrom(&testConfig{}, source, WithStrict("APP_"))
+	if !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("expected tag error, got %v", err)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"TIMEOUT", "TIMEOUT", 0},
+		{"TIMOUT", "TIMEOUT", 1},
+		{"HOTS", "HOST", 2},
+		{"", "ABC", 3},
+		{"KITTEN", "SITTING", 3},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Fatalf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLoadStrictAcceptsDeclaredKeysThatWereNotUsed(t *testing.T) {
+	type testConfig struct {
+		Port  int    `env:"APP_PORT,alias=APP_OLD_PORT"`
+		Token string `env:"APP_TOKEN"`
+	}
+
+	source := MapSource{
+		"APP_PORT":       "8080",
+		"APP_OLD_PORT":   "9090",
+		"APP_TOKEN":      "t",
+		"APP_TOKEN_FILE": "/run/secrets/token",
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source, WithStrict("APP_")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 8080 || cfg.Token != "t" {
+		t.Fatalf("expected the primary keys to win, got %+v", cfg)
+	}
+}
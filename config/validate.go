@@ -0,0 +1,204 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Validator is implemented by config structs that check cross-field rules. Load calls
+// Validate on the root struct and every nested struct after their fields are loaded.
+type Validator interface {
+	Validate() error
+}
+
+// validateStruct calls Validate on target when it, or a pointer to it, implements Validator.
+func validateStruct(target reflect.Value) error {
+	if target.CanAddr() {
+		if validator, ok := target.Addr().Interface().(Validator); ok {
+			return validator.Validate()
+		}
+	}
+	if validator, ok := target.Interface().(Validator); ok {
+		return validator.Validate()
+	}
+	return nil
+}
+
+// validateValue checks a parsed value against the constraint options of its tag.
+func validateValue(value reflect.Value, opts fieldOptions) error {
+	switch {
+	case value.Kind() == reflect.Pointer && !value.IsNil():
+		return validateValue(value.Elem(), opts)
+	case isOptional(value.Type()) && value.CanAddr():
+		return validateValue(value.Addr().Interface().(optionalValue).optionalTarget(), opts)
+	}
+
+	t := value.Type()
+	switch kind := value.Kind(); {
+	case kind == reflect.String:
+		return validateString(value.String(), opts)
+	case kind == reflect.Slice, kind == reflect.Map:
+		return validateCollection(value.Len(), t.Elem().Kind() == reflect.String, t.String(), opts)
+	case t == timeDurationType:
+		return validateDuration(time.Duration(value.Int()), t.String(), opts)
+	case isSignedInteger(kind):
+		return validateInt(value.Int(), t.String(), opts)
+	case isUnsignedInteger(kind):
+		return validateUint(value.Uint(), t.String(), opts)
+	case kind == reflect.Float32, kind == reflect.Float64:
+		return validateFloat(value.Float(), t.String(), opts)
+	default:
+		return validateScalar(t.String(), opts, false)
+	}
+}
+
+// validateString applies oneof, pattern and the length options to a string.
+func validateString(value string, opts fieldOptions) error {
+	if len(opts.oneOf) > 0 && !slices.Contains(opts.oneOf, value) {
+		return validationErrorf("value is not in enum %v", opts.oneOf)
+	}
+	if opts.pattern != nil && !opts.pattern.MatchString(value) {
+		return validationErrorf("value does not match pattern %q", opts.pattern)
+	}
+	return validateLength(len(value), opts)
+}
+
+// validateCollection applies the length options to a slice or map of the named
+// type. stringElems reports whether its elements are strings, which pattern
+// requires; the elements themselves are checked as they are decoded.
+func validateCollection(length int, stringElems bool, typeName string, opts fieldOptions) error {
+	if opts.pattern != nil && !stringElems {
+		return tagErrorf("pattern requires string values, got %s", typeName)
+	}
+	return validateLength(length, opts)
+}
+
+// validateScalar rejects the string and length options on a scalar of the named
+// type, and min and max unless ranged reports that the type is ordered.
+func validateScalar(typeName string, opts fieldOptions, ranged bool) error {
+	switch {
+	case opts.pattern != nil:
+		return tagErrorf("pattern requires string values, got %s", typeName)
+	case opts.length >= 0 || opts.minLen >= 0 || opts.maxLen >= 0:
+		return tagErrorf("len, minlen and maxlen require a string, slice or map, got %s", typeName)
+	case !ranged && (opts.min != "" || opts.max != ""):
+		return tagErrorf("min and max require a number, duration, string, slice or map, got %s", typeName)
+	default:
+		return nil
+	}
+}
+
+func validateDuration(value time.Duration, typeName string, opts fieldOptions) error {
+	if err := validateScalar(typeName, opts, true); err != nil {
+		return err
+	}
+	return validateRange(value, opts, time.ParseDuration)
+}
+
+func validateInt(value int64, typeName string, opts fieldOptions) error {
+	if err := validateScalar(typeName, opts, true); err != nil {
+		return err
+	}
+	if opts.format == formatBytes {
+		return validateRange(value, opts, parseBytes)
+	}
+	return validateRange(value, opts, func(raw string) (int64, error) {
+		return strconv.ParseInt(raw, 10, 64)
+	})
+}
+
+func validateUint(value uint64, typeName string, opts fieldOptions) error {
+	if err := validateScalar(typeName, opts, true); err != nil {
+		return err
+	}
+	return validateRange(value, opts, func(raw string) (uint64, error) {
+		return strconv.ParseUint(raw, 10, 64)
+	})
+}
+
+func validateFloat(value float64, typeName string, opts fieldOptions) error {
+	if err := validateScalar(typeName, opts, true); err != nil {
+		return err
+	}
+	return validateRange(value, opts, func(raw string) (float64, error) {
+		return strconv.ParseFloat(raw, 64)
+	})
+}
+
+// validateLength applies len, minlen and maxlen, and min and max as length bounds.
+func validateLength(length int, opts fieldOptions) error {
+	if opts.length >= 0 && length != opts.length {
+		return validationErrorf("length %d does not equal %d", length, opts.length)
+	}
+
+	minLen, maxLen := opts.minLen, opts.maxLen
+	for _, bound := range []struct {
+		raw   string
+		limit *int
+	}{
+		{raw: opts.min, limit: &minLen},
+		{raw: opts.max, limit: &maxLen},
+	} {
+		if bound.raw == "" {
+			continue
+		}
+		value, err := strconv.Atoi(bound.raw)
+		if err != nil || value < 0 {
+			return tagErrorf("invalid length bound %q: expected a non-negative integer", bound.raw)
+		}
+		if *bound.limit < 0 {
+			*bound.limit = value
+		}
+	}
+
+	if minLen >= 0 && length < minLen {
+		return validationErrorf("length %d is less than minimum %d", length, minLen)
+	}
+	if maxLen >= 0 && length > maxLen {
+		return validationErrorf("length %d is greater than maximum %d", length, maxLen)
+	}
+	return nil
+}
+
+// validateRange applies min and max, parsing each bound with parse.
+func validateRange[T int64 | uint64 | float64 | time.Duration](value T, opts fieldOptions, parse func(string) (T, error)) error {
+	if opts.min != "" {
+		limit, err := parse(opts.min)
+		if err != nil {
+			return tagErrorf("invalid min %q: %v", opts.min, err)
+		}
+		if value < limit {
+			return validationErrorf("value is less than minimum %s", opts.min)
+		}
+	}
+	if opts.max != "" {
+		limit, err := parse(opts.max)
+		if err != nil {
+			return tagErrorf("invalid max %q: %v", opts.max, err)
+		}
+		if value > limit {
+			return validationErrorf("value is greater than maximum %s", opts.max)
+		}
+	}
+	return nil
+}
+
+func isSignedInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+func isUnsignedInteger(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
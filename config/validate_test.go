@@ -0,0 +1,221 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadAcceptsValuesWithinBounds(t *testing.T) {
+	type testConfig struct {
+		Port     int               `env:"PORT,min=1,max=65535"`
+		Timeout  time.Duration     `env:"TIMEOUT,min=100ms,max=1m"`
+		MaxBytes int64             `env:"MAX_BYTES,format=bytes,max=1GiB"`
+		Ratio    float64           `env:"RATIO,min=0,max=1"`
+		Workers  uint              `env:"WORKERS,min=1"`
+		Name     string            `env:"NAME,minlen=3,maxlen=12,pattern=^[a-z]{1,12}$"`
+		Code     string            `env:"CODE,len=2"`
+		Hosts    []string          `env:"HOSTS,min=1,max=3,pattern=^[a-z.]+$"`
+		Labels   map[string]string `env:"LABELS,maxlen=2"`
+	}
+
+	source := MapSource{
+		"PORT":      "8080",
+		"TIMEOUT":   "30s",
+		"MAX_BYTES": "512MiB",
+		"RATIO":     "0.5",
+		"WORKERS":   "4",
+		"NAME":      "envchain",
+		"CODE":      "us",
+		"HOSTS":     "a.example,b.example",
+		"LABELS":    "team=core",
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, source); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Port != 8080 || cfg.Name != "envchain" || len(cfg.Hosts) != 2 {
+		t.Fatalf("expected values to load, got %+v", cfg)
+	}
+}
+
+func TestLoadRejectsValuesOutsideBounds(t *testing.T) {
+	type testConfig struct {
+		Port     int               `env:"PORT,min=1,max=65535"`
+		Timeout  time.Duration     `env:"TIMEOUT,max=1m"`
+		MaxBytes int64             `env:"MAX_BYTES,format=bytes,max=1GiB"`
+		Name     string            `env:"NAME,pattern=^[a-z]+$"`
+		Code     string            `env:"CODE,len=2"`
+		Hosts    []string          `env:"HOSTS,min=1"`
+		Labels   map[string]string `env:"LABELS,maxlen=1"`
+		Debug    bool              `env:"DEBUG,min=1"`
+	}
+
+	source := MapSource{
+		"PORT":      "0",
+		"TIMEOUT":   "10h",
+		"MAX_BYTES": "2GiB",
+		"NAME":      "Env-Chain",
+		"CODE":      "usa",
+		"HOSTS":     "",
+		"LABELS":    "a=1,b=2",
+		"DEBUG":     "true",
+	}
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, source)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+
+	got := err.Error()
+	for _, want := range []string{
+		`field Port: env "PORT" value "0": value is less than minimum 1`,
+		`field Timeout: env "TIMEOUT" value "10h": value is greater than maximum 1m`,
+		`field MaxBytes: env "MAX_BYTES" value "2GiB": value is greater than maximum 1GiB`,
+		`field Name: env "NAME" value "Env-Chain": value does not match pattern "^[a-z]+$"`,
+		`field Code: env "CODE" value "usa": length 3 does not equal 2`,
+		`field Hosts: env "HOSTS" value "": length 0 is less than minimum 1`,
+		`field Labels: env "LABELS" value "a=1,b=2": length 2 is greater than maximum 1`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected error to contain %q, got %q", want, got)
+		}
+	}
+
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected *LoadError, got %T", err)
+	}
+	for _, fieldErr := range loadErr.Errors {
+		want := KindValidation
+		if fieldErr.Field == "Debug" {
+			want = KindTag
+		}
+		if fieldErr.Kind != want {
+			t.Fatalf("expected %s to be a %s error, got %s", fieldErr.Field, want, fieldErr.Kind)
+		}
+	}
+	if cfg.Port != 0 || cfg.Name != "" {
+		t.Fatalf("expected invalid values to be left unset, got %+v", cfg)
+	}
+}
+
+func TestLoadRejectsInvalidValidationOptions(t *testing.T) {
+	type testConfig struct {
+		Pattern string `env:"PATTERN,pattern=[a-"`
+		Length  string `env:"LENGTH,len=-1"`
+	}
+
+	var cfg testConfig
+	err := LoadFrom(&cfg, MapSource{})
+	if !errors.Is(err, ErrInvalidTag) {
+		t.Fatalf("expected invalid tag error, got %v", err)
+	}
+	if got := err.Error(); !strings.Contains(got, "field Pattern") || !strings.Contains(got, "field Length") {
+		t.Fatalf("expected both fields to be reported, got %q", got)
+	}
+}
+
+type poolConfig struct {
+	MinConns int `env:"MIN_CONNS,default=1"`
+	MaxConns int `env:"MAX_CONNS,default=10"`
+}
+
+func (p poolConfig) Validate() error {
+	if p.MinConns > p.MaxConns {
+		return errors.New("MIN_CONNS must not exceed MAX_CONNS")
+	}
+	return nil
+}
+
+type tlsConfig struct {
+	Cert string `env:"TLS_CERT"`
+	Key  string `env:"TLS_KEY"`
+}
+
+func (c *tlsConfig) Validate() error {
+	if c.Cert != "" && c.Key == "" {
+		return errors.New("TLS_CERT requires TLS_KEY")
+	}
+	return nil
+}
+
+type serviceConfig struct {
+	Port int `env:"PORT,default=8080"`
+	Pool poolConfig
+	TLS  *tlsConfig
+}
+
+func (c *serviceConfig) Validate() error {
+	if c.Port == 9999 {
+		return errors.New("port 9999 is reserved")
+	}
+	return nil
+}
+
+func TestLoadCallsValidateOnStructs(t *testing.T) {
+	source := MapSource{
+		"PORT":      "9999",
+		"MIN_CONNS": "20",
+		"TLS_CERT":  "/etc/tls/cert.pem",
+	}
+
+	var cfg serviceConfig
+	err := LoadFrom(&cfg, source)
+	if err == nil {
+		t.Fatal("expected validation errors")
+	}
+
+	var loadErr *LoadError
+	if !errors.As(err, &loadErr) {
+		t.Fatalf("expected *LoadError, got %T", err)
+	}
+	want := map[string]string{
+		"":     "port 9999 is reserved",
+		"Pool": "field Pool: MIN_CONNS must not exceed MAX_CONNS",
+		"TLS":  "field TLS: TLS_CERT requires TLS_KEY",
+	}
+	if len(loadErr.Errors) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), err)
+	}
+	for _, fieldErr := range loadErr.Errors {
+		if fieldErr.Kind != KindValidation || fieldErr.Error() != want[fieldErr.Field] {
+			t.Fatalf("unexpected struct error %+v (%q)", *fieldErr, fieldErr.Error())
+		}
+	}
+}
+
+type listenerConfig struct {
+	Addr string `env:"LISTEN_ADDR"`
+}
+
+func (c listenerConfig) Validate() error {
+	if c.Addr == "" {
+		return errors.New("LISTEN_ADDR is required when a listener is configured")
+	}
+	return nil
+}
+
+func TestLoadSkipsValidateForUnsetOptionalStructs(t *testing.T) {
+	type testConfig struct {
+		Listener *listenerConfig
+	}
+
+	var cfg testConfig
+	if err := LoadFrom(&cfg, MapSource{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Listener != nil {
+		t.Fatalf("expected unset listener to stay nil, got %+v", cfg.Listener)
+	}
+
+	if err := LoadFrom(&cfg, MapSource{"LISTEN_ADDR": ":8080"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Listener == nil || cfg.Listener.Addr != ":8080" {
+		t.Fatalf("expected listener to load, got %+v", cfg.Listener)
+	}
+}
@@ -0,0 +1,177 @@
+// Package envtypes classifies go/types types the way config.Load classifies
+// reflect types, for tools that check env tags without running Load.
+package envtypes
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+
+	"github.com/stuft2/envchain/config/envchainrt"
+)
+
+const configPath = "github.com/stuft2/envchain/config"
+
+// Shape classifies t the way config.Load does before interpreting tags.
+func Shape(t types.Type) envchainrt.FieldShape {
+	switch {
+	case isNestedStruct(t):
+		return envchainrt.ShapeStruct
+	case isStructCollection(t):
+		return envchainrt.ShapeCollection
+	default:
+		return envchainrt.ShapeValue
+	}
+}
+
+func isNestedStruct(t types.Type) bool {
+	if pointer, ok := t.Underlying().(*types.Pointer); ok {
+		t = pointer.Elem()
+	}
+	if _, ok := t.Underlying().(*types.Struct); !ok {
+		return false
+	}
+	return !IsNamed(t, "time", "Time") && !IsNamed(t, "net/url", "URL") && !IsOptional(t) && !IsUnmarshaler(t)
+}
+
+func isStructCollection(t types.Type) bool {
+	switch t := t.Underlying().(type) {
+	case *types.Slice:
+		return isNestedStruct(t.Elem())
+	case *types.Map:
+		key, ok := t.Key().Underlying().(*types.Basic)
+		return ok && key.Kind() == types.String && isNestedStruct(t.Elem())
+	default:
+		return false
+	}
+}
+
+// CheckValue reports why a value field of type t cannot be decoded with the given
+// format and layout, mirroring the order in which config.Load picks a decoder.
+// parsers holds the types, spelled as by TypeName, that have parsers registered
+// at run time.
+func CheckValue(t types.Type, format, layout string, parsers map[string]bool) error {
+	if parsers[TypeName(t)] {
+		return nil
+	}
+
+	switch format {
+	case "json":
+		if !IsOptional(t) {
+			return nil
+		}
+	case "base64", "hex":
+		switch t.Underlying().(type) {
+		case *types.Pointer, *types.Slice, *types.Map:
+		default:
+			if IsString(t) {
+				return nil
+			}
+			if !IsOptional(t) {
+				return fmt.Errorf("format=%s requires a string or []byte field, got %s", format, types.TypeString(t, nil))
+			}
+		}
+		if IsByteSlice(t) {
+			return nil
+		}
+	}
+
+	switch {
+	case IsNamed(t, "time", "Duration"), IsNamed(t, "net/url", "URL"):
+		return nil
+	case IsNamed(t, "time", "Time"):
+		if layout == "" {
+			return errors.New("time.Time fields require layout")
+		}
+		return nil
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		return CheckValue(u.Elem(), format, layout, parsers)
+	}
+	if IsOptional(t) {
+		return CheckValue(t.(*types.Named).TypeArgs().At(0), format, layout, parsers)
+	}
+	if IsUnmarshaler(t) {
+		return nil
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		return errors.New("nested structs are not supported")
+	case *types.Basic:
+		if u.Info()&(types.IsString|types.IsBoolean|types.IsInteger|types.IsFloat) != 0 && u.Info()&types.IsUntyped == 0 {
+			return nil
+		}
+	case *types.Slice:
+		return CheckValue(u.Elem(), format, layout, parsers)
+	case *types.Map:
+		keyFormat := format
+		if keyFormat == "json" || keyFormat == "base64" || keyFormat == "hex" {
+			keyFormat = ""
+		}
+		if err := CheckValue(u.Key(), keyFormat, layout, parsers); err != nil {
+			return err
+		}
+		return CheckValue(u.Elem(), format, layout, parsers)
+	}
+	return fmt.Errorf("unsupported field type %s", types.TypeString(t, nil))
+}
+
+// TypeName spells t with package names rather than paths, such as *regexp.Regexp.
+func TypeName(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		return pkg.Name()
+	})
+}
+
+// IsNamed reports whether t is the named type pkgPath.name, such as time.Duration.
+func IsNamed(t types.Type, pkgPath, name string) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkgPath && obj.Name() == name
+}
+
+// IsOptional reports whether t is an instance of config.Optional.
+func IsOptional(t types.Type) bool {
+	return IsNamed(t, configPath, "Optional")
+}
+
+// IsString reports whether t is a string type.
+func IsString(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsString != 0
+}
+
+// IsByteSlice reports whether t is a slice of bytes.
+func IsByteSlice(t types.Type) bool {
+	slice, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	basic, ok := slice.Elem().Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Uint8
+}
+
+// IsUnmarshaler reports whether *t implements encoding.TextUnmarshaler,
+// encoding.BinaryUnmarshaler or flag.Value.
+func IsUnmarshaler(t types.Type) bool {
+	pointer := types.NewPointer(t)
+	return hasMethod(pointer, "UnmarshalText", 1, 1) ||
+		hasMethod(pointer, "UnmarshalBinary", 1, 1) ||
+		(hasMethod(pointer, "Set", 1, 1) && hasMethod(pointer, "String", 0, 1))
+}
+
+func hasMethod(t types.Type, name string, params, results int) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	method, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	sig := method.Signature()
+	return sig.Params().Len() == params && sig.Results().Len() == results
+}